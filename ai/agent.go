@@ -0,0 +1,112 @@
+package ai
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// Agent takes the decisions of an AI seat. An agent is created for one game
+// and may keep memory between its decisions.
+type Agent interface {
+	// Bid predicts how many tricks the seat will win this turn
+	Bid(view *engine.PlayerView) int
+	// Play picks one of the legal cards to play to the current trick
+	Play(view *engine.PlayerView) card.Card
+	// Trump names the trump symbol when the seat deals and turns up a Wizard
+	Trump(view *engine.PlayerView) card.Symbol
+}
+
+// Observer is implemented by agents that follow the events of the turn,
+// from their own seat's side of the table.
+type Observer interface {
+	Observe(e engine.Event)
+}
+
+type Level string
+
+// Difficulty Levels
+const (
+	Easy   Level = "easy"   // Basic probability, random legal play
+	Medium Level = "medium" // Card counting
+	Hard   Level = "hard"   // Opponent modeling: a few deals played out against modeled opponents
+	Expert Level = "expert" // Monte Carlo simulations over many deals
+	// Learned plays as Medium but bids with the model fitted by train-bidder
+	Learned Level = "learned"
+	// CFR plays the small turns by the strategy computed by 'wizard cfr', the
+	// rest as Medium
+	CFR Level = "cfr"
+	// Cloned plays as the player imitated by the clone fitted by 'wizard clone'
+	Cloned Level = "clone"
+	// Tree searches one tree of the turn's decisions over the sampled deals
+	// (ISMCTS), as bounded by DefaultTreeSearch
+	Tree Level = "ismcts"
+)
+
+var Levels = []Level{Easy, Medium, Hard, Expert, Learned, CFR, Cloned, Tree}
+
+// LevelByName looks a difficulty level up by name; an empty name is Medium.
+func LevelByName(name string) (Level, error) {
+	if name == "" {
+		return Medium, nil
+	}
+	for _, level := range Levels {
+		if strings.EqualFold(string(level), name) {
+			return level, nil
+		}
+	}
+	return "", fmt.Errorf("unknown AI difficulty '%s'", name)
+}
+
+// New creates the agent of a difficulty level, playing in the given personality.
+func New(level Level, personality Personality, seed int64) Agent {
+	return NewTuned(level, personality, DefaultWeights, seed)
+}
+
+// NewTuned creates the agent of a difficulty level that bids and plays on its
+// own weights rather than the default ones.
+func NewTuned(level Level, personality Personality, weights Weights, seed int64) Agent {
+	switch level {
+	case Easy:
+		return &random{heuristic: heuristic{Personality: personality, weights: &weights}, rng: rand.New(rand.NewSource(seed))}
+	case Hard:
+		return newSimulation(personality, Search{Iterations: hardSamples}, seed).modeled().tuned(&weights)
+	case Expert:
+		return newSimulation(personality, ExpertSearch, seed).tuned(&weights).solving(ExpertEndgame)
+	case Learned:
+		bidder := NewLearnedBidder(BidderModel, personality)
+		bidder.weights = &weights
+		return bidder
+	case CFR:
+		agent := NewCFRAgent(CFRStrategy, personality, seed)
+		agent.weights = &weights
+		return agent
+	case Cloned:
+		agent := NewCloneAgent(CloneModel, personality, seed)
+		agent.weights = &weights
+		return agent
+	case Tree:
+		agent := NewISMCTS(personality, DefaultTreeSearch, seed)
+		agent.weights = &weights
+		agent.rollout.weights = &weights
+		return agent
+	}
+	return &heuristic{Personality: personality, counting: true, weights: &weights}
+}
+
+// random bids on the basic probabilities and plays any legal card.
+type random struct {
+	heuristic
+	rng *rand.Rand
+}
+
+func (r *random) Play(view *engine.PlayerView) card.Card {
+	legal := view.Trick.LegalPlays(view.Hand)
+	pick := legal[r.rng.Intn(len(legal))]
+	if r.explaining {
+		r.rationale = &Rationale{Decision: "play " + pick.String(), Rule: "a legal card at random"}
+	}
+	return pick
+}
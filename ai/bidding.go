@@ -0,0 +1,171 @@
+// Package ai drives the seats that aren't played from the console.
+package ai
+
+import (
+	"math"
+	"wizard/card"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// knowledge is what a seat counts on when weighing its cards: the cards it
+// hasn't seen, which may still beat them.
+type knowledge struct {
+	unseen     []card.Card
+	opponents  int // cards still held by the opponents
+	tricks     int // tricks left, the current one included
+	numPlayers int
+	weights    Weights
+}
+
+// handKnowledge only takes the seat's own hand off the deck, as a player who
+// doesn't count cards would.
+func handKnowledge(hand []card.Card, numPlayers int) knowledge {
+	unseen := deck.InitDeck()
+	for _, c := range hand {
+		for i, u := range unseen {
+			if u == c {
+				unseen = append(unseen[:i], unseen[i+1:]...)
+				break
+			}
+		}
+	}
+	return knowledge{
+		unseen:     unseen,
+		opponents:  len(hand) * (numPlayers - 1),
+		tricks:     len(hand),
+		numPlayers: numPlayers,
+		weights:    DefaultWeights,
+	}
+}
+
+// countedKnowledge also remembers the trump card and every card played this turn.
+func countedKnowledge(view *engine.PlayerView) knowledge {
+	k := knowledge{
+		unseen:     view.Unseen(),
+		tricks:     len(view.Hand),
+		numPlayers: view.NumPlayers(),
+		weights:    DefaultWeights,
+	}
+	for seat := range view.NumPlayers() {
+		if seat != view.Seat {
+			k.opponents += view.CardsLeft(seat)
+		}
+	}
+	return k
+}
+
+// beatChance is the probability that one given unseen card is in an
+// opponent's hand and gets played on the same trick as ours.
+func (k knowledge) beatChance() float64 {
+	if len(k.unseen) == 0 {
+		return 0
+	}
+	inOpponentHand := math.Min(1, float64(k.opponents)/float64(len(k.unseen)))
+	return inOpponentHand * math.Min(1, k.weights.SpendRate/float64(k.tricks+1))
+}
+
+// higher counts the unseen cards of c's symbol above it
+func (k knowledge) higher(c card.Card) int {
+	count := 0
+	for _, u := range k.unseen {
+		if !u.IsWizard && !u.IsJoker && u.Symbol == c.Symbol && u.Number > c.Number {
+			count++
+		}
+	}
+	return count
+}
+
+func (k knowledge) wizards() int {
+	return countWizards(k.unseen)
+}
+
+func (k knowledge) symbol(symbol card.Symbol) int {
+	return countSymbol(k.unseen, symbol)
+}
+
+// beaters weighs the unseen cards that may take a trick from c: the Wizards,
+// the higher cards of its symbol and, for an off-suit card, the trumps of
+// opponents who can't follow its suit.
+func (k knowledge) beaters(c card.Card, trump card.Symbol) float64 {
+	beaters := float64(k.higher(c) + k.wizards())
+
+	if trump != "" && c.Symbol != trump && len(k.unseen) > 0 {
+		perOpponent := float64(k.opponents) / float64(k.numPlayers-1)
+		void := math.Pow(1-float64(k.symbol(c.Symbol))/float64(len(k.unseen)), perOpponent)
+		beaters += float64(k.symbol(trump)) * void
+	}
+	return beaters
+}
+
+// sure reports whether no unseen card can beat c.
+func (k knowledge) sure(c card.Card, trump card.Symbol) bool {
+	return c.IsWizard || (!c.IsJoker && k.beaters(c, trump) == 0)
+}
+
+// EstimateTricks returns the number of tricks a hand is expected to win:
+//
+//	expectedTricks = countWizards(hand) +
+//	                 evaluateTrumpCards(hand, trump) +
+//	                 evaluateOffSuitCards(hand, trump)
+func EstimateTricks(hand []card.Card, trump card.Symbol, numPlayers int) float64 {
+	return estimateTricks(hand, trump, handKnowledge(hand, numPlayers))
+}
+
+func estimateTricks(hand []card.Card, trump card.Symbol, k knowledge) float64 {
+	expected := float64(countWizards(hand))
+
+	for _, c := range hand {
+		if !c.IsWizard {
+			expected += cardWinProbability(c, trump, k)
+		}
+	}
+
+	return expected
+}
+
+func cardWinProbability(c card.Card, trump card.Symbol, k knowledge) float64 {
+	switch {
+	case c.IsWizard:
+		return 1
+	case c.IsJoker:
+		return 0
+	case trump != "" && c.Symbol == trump:
+		return trumpWinProbability(c, trump, k)
+	}
+	return offSuitWinProbability(c, trump, k)
+}
+
+// trumpWinProbability: only higher trumps and Wizards beat a trump
+func trumpWinProbability(c card.Card, trump card.Symbol, k knowledge) float64 {
+	return math.Pow(1-k.beatChance(), k.beaters(c, trump))
+}
+
+// offSuitWinProbability: an off-suit card can also be trumped by a player out of its suit
+func offSuitWinProbability(c card.Card, trump card.Symbol, k knowledge) float64 {
+	return k.weights.OffSuitFactor * math.Pow(1-k.beatChance(), k.beaters(c, trump))
+}
+
+func clampBid(bid, handSize int) int {
+	return max(0, min(bid, handSize))
+}
+
+func countWizards(hand []card.Card) int {
+	count := 0
+	for _, c := range hand {
+		if c.IsWizard {
+			count++
+		}
+	}
+	return count
+}
+
+func countSymbol(hand []card.Card, symbol card.Symbol) int {
+	count := 0
+	for _, c := range hand {
+		if !c.IsWizard && !c.IsJoker && c.Symbol == symbol {
+			count++
+		}
+	}
+	return count
+}
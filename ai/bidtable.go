@@ -0,0 +1,254 @@
+package ai
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+	"wizard/card"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// BidTableVersion is the version of the bid table files this build reads.
+const BidTableVersion = 1
+
+// BidTable maps the pattern of a hand to the distribution of the tricks it
+// takes, measured offline by playing many deals, so that a bid is looked up
+// rather than simulated. A pattern is written by HandPattern; when the exact
+// pattern was seen too seldom, a coarser one stands in for it.
+type BidTable struct {
+	Version int
+	Seed    int64
+	Deals   int // played for every number of players and hand size
+	// by pattern, the coarse patterns included
+	Patterns map[string]BidPattern
+}
+
+// BidPattern is what the deals told of a pattern.
+type BidPattern struct {
+	Samples int
+	Tricks  []float32 // chance of taking every number of tricks
+}
+
+// HandPattern writes the canonical features of a hand seen from a seat: the
+// players, the hand size and the seat's order in the bidding, whether there
+// is a trump, then the Wizards and Jokers, the trumps numbered 11 to 13, 6 to
+// 10 and 1 to 5, and the off-suit 13s, 12s and 10s and 11s. The coarse
+// pattern keeps the trumps and the off-suit 12s and 13s as counts.
+func HandPattern(hand []card.Card, trump card.Symbol, numPlayers, position int, coarse bool) string {
+	var wizards, jokers, high, mid, low, kings, queens, tens int
+	for _, c := range hand {
+		switch {
+		case c.IsWizard:
+			wizards++
+		case c.IsJoker:
+			jokers++
+		case trump != "" && c.Symbol == trump && c.Number >= 11:
+			high++
+		case trump != "" && c.Symbol == trump && c.Number >= 6:
+			mid++
+		case trump != "" && c.Symbol == trump:
+			low++
+		case c.Number == 13:
+			kings++
+		case c.Number == 12:
+			queens++
+		case c.Number >= 10:
+			tens++
+		}
+	}
+	prefix := fmt.Sprintf("%d/%d/%d %s W%d J%d", numPlayers, len(hand), position, trumpHeld(trump), wizards, jokers)
+	if coarse {
+		return fmt.Sprintf("%s T%d H%d ~", prefix, high+mid+low, kings+queens)
+	}
+	return fmt.Sprintf("%s T%d.%d.%d H%d.%d.%d", prefix, high, mid, low, kings, queens, tens)
+}
+
+// Distribution looks the hand of the view up, false when the table knows
+// neither its pattern nor the coarse one.
+func (t *BidTable) Distribution(view *engine.PlayerView) ([]float64, bool) {
+	if t == nil {
+		return nil, false
+	}
+	position := Position(view)
+	pattern, ok := t.Patterns[HandPattern(view.Hand, view.TrumpSuit, view.NumPlayers(), position, false)]
+	if !ok {
+		pattern, ok = t.Patterns[HandPattern(view.Hand, view.TrumpSuit, view.NumPlayers(), position, true)]
+	}
+	if !ok || len(pattern.Tricks) != len(view.Hand)+1 {
+		return nil, false
+	}
+	dist := make([]float64, len(pattern.Tricks))
+	for i, q := range pattern.Tricks {
+		dist[i] = float64(q)
+	}
+	return dist, true
+}
+
+// GenerateBidTable plays deals turns of every hand size for each number of
+// players, every seat played by the medium level, and keeps the tricks every
+// hand took under its pattern and its coarse one. Patterns seen fewer than
+// minSamples times are left out. The table depends on the seed only, however
+// the deals are spread over the CPUs.
+func GenerateBidTable(players []int, deals, minSamples int, seed int64) *BidTable {
+	type job struct{ players, handSize int }
+	var jobs []job
+	for _, n := range players {
+		for handSize := 1; handSize <= 60/n; handSize++ {
+			jobs = append(jobs, job{n, handSize})
+		}
+	}
+
+	counts := make([]map[string][]int, len(jobs))
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				j := jobs[i]
+				rng := rand.New(rand.NewSource(seed*1000 + int64(j.players*100+j.handSize)))
+				counts[i] = countTricks(j.players, j.handSize, deals, rng)
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	table := &BidTable{Version: BidTableVersion, Seed: seed, Deals: deals, Patterns: map[string]BidPattern{}}
+	for _, byPattern := range counts {
+		for key, tricks := range byPattern {
+			samples := 0
+			for _, count := range tricks {
+				samples += count
+			}
+			if samples < minSamples {
+				continue
+			}
+			pattern := BidPattern{Samples: samples, Tricks: make([]float32, len(tricks))}
+			for k, count := range tricks {
+				pattern.Tricks[k] = float32(count) / float32(samples)
+			}
+			table.Patterns[key] = pattern
+		}
+	}
+	return table
+}
+
+// countTricks plays deals turns and counts, for every pattern, the hands
+// that took every number of tricks.
+func countTricks(numPlayers, handSize, deals int, rng *rand.Rand) map[string][]int {
+	reference := &heuristic{Personality: Balanced, counting: true}
+	counts := map[string][]int{}
+	for range deals {
+		turnDeck := deck.InitDeck()
+		turnDeck.ShuffleWith(rng)
+		state := engine.NewState(numPlayers, rng.Intn(numPlayers), handSize, turnDeck)
+		dealt := make([][]card.Card, numPlayers)
+		for seat, hand := range state.Hands {
+			dealt[seat] = slices.Clone(hand)
+		}
+
+		for state.Phase != engine.Done {
+			view := state.View(state.ToAct)
+			switch state.Phase {
+			case engine.Choosing:
+				state.Apply(engine.TrumpOf(reference.Trump(&view)))
+			case engine.Bidding:
+				state.Apply(engine.BidOf(reference.Bid(&view)))
+			default:
+				state.Apply(engine.PlayOf(reference.Play(&view)))
+			}
+		}
+
+		for seat, hand := range dealt {
+			position := (seat - state.Dealer - 1 + numPlayers) % numPlayers
+			for _, coarse := range []bool{false, true} {
+				key := HandPattern(hand, state.TrumpSuit, numPlayers, position, coarse)
+				if counts[key] == nil {
+					counts[key] = make([]int, handSize+1)
+				}
+				counts[key][state.Won[seat]]++
+			}
+		}
+	}
+	return counts
+}
+
+// bidTableFile is a table as saved: the patterns in order, so that the same
+// seed writes the same file.
+type bidTableFile struct {
+	Version  int
+	Seed     int64
+	Deals    int
+	Keys     []string
+	Patterns []BidPattern
+}
+
+// LoadBidTable reads a table saved by Save.
+func LoadBidTable(path string) (*BidTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var saved bidTableFile
+	if err := gob.NewDecoder(reader).Decode(&saved); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if saved.Version != BidTableVersion {
+		return nil, fmt.Errorf("%s holds bid tables of version %d, this build reads version %d", path, saved.Version, BidTableVersion)
+	}
+	if len(saved.Keys) != len(saved.Patterns) {
+		return nil, fmt.Errorf("%s: %d patterns for %d keys", path, len(saved.Patterns), len(saved.Keys))
+	}
+	t := &BidTable{Version: saved.Version, Seed: saved.Seed, Deals: saved.Deals, Patterns: make(map[string]BidPattern, len(saved.Keys))}
+	for i, key := range saved.Keys {
+		t.Patterns[key] = saved.Patterns[i]
+	}
+	return t, nil
+}
+
+// Save writes the table gzipped.
+func (t *BidTable) Save(path string) error {
+	saved := bidTableFile{Version: t.Version, Seed: t.Seed, Deals: t.Deals}
+	for key := range t.Patterns {
+		saved.Keys = append(saved.Keys, key)
+	}
+	slices.Sort(saved.Keys)
+	for _, key := range saved.Keys {
+		saved.Patterns = append(saved.Patterns, t.Patterns[key])
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(file)
+	if err := gob.NewEncoder(writer).Encode(saved); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// BidTables, when set, is looked up by the simulating levels instead of
+// simulating their bids.
+var BidTables *BidTable
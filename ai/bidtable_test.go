@@ -0,0 +1,58 @@
+package ai_test
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"wizard/ai"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// TestBidTables generates small tables twice from one seed, saves them and
+// checks the hard level bids by them
+func TestBidTables(t *testing.T) {
+	table := ai.GenerateBidTable([]int{3}, 150, 5, 1)
+	again := ai.GenerateBidTable([]int{3}, 150, 5, 1)
+	if len(table.Patterns) == 0 || len(table.Patterns) != len(again.Patterns) {
+		t.Fatalf("%d and %d patterns from one seed", len(table.Patterns), len(again.Patterns))
+	}
+	for key, pattern := range table.Patterns {
+		if !slices.Equal(again.Patterns[key].Tricks, pattern.Tricks) {
+			t.Fatalf("%s: %v and %v from one seed", key, pattern.Tricks, again.Patterns[key].Tricks)
+		}
+		sum := float32(0)
+		for _, q := range pattern.Tricks {
+			sum += q
+		}
+		if pattern.Samples < 5 || math.Abs(float64(sum)-1) > 1e-4 {
+			t.Errorf("%s: %d samples, chances summing to %.3f", key, pattern.Samples, sum)
+		}
+	}
+
+	path := t.TempDir() + "/bidtables.gz"
+	if err := table.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ai.LoadBidTable(path)
+	if err != nil || len(loaded.Patterns) != len(table.Patterns) || loaded.Seed != 1 {
+		t.Fatalf("loaded %d patterns: %v", len(loaded.Patterns), err)
+	}
+
+	// A lone Wizard in the first turn is a sure trick
+	wizard := card.Card{IsWizard: true, Number: -1}
+	view := engine.PlayerView{Seat: 1, Dealer: 0, HandSize: 1, Hand: []card.Card{wizard}, TrumpSuit: card.Red,
+		Bids: []int{-1, -1, -1}, Won: make([]int, 3), Trick: engine.NewTrick(card.Red)}
+	dist, ok := loaded.Distribution(&view)
+	if !ok || dist[1] < 0.99 {
+		t.Fatalf("a lone Wizard takes %v tricks", dist)
+	}
+	ai.BidTables = loaded
+	defer func() { ai.BidTables = nil }()
+	hard := ai.New(ai.Hard, ai.Balanced, 1)
+	hard.(ai.Explainer).Explain()
+	if bid := hard.Bid(&view); bid != 1 || !strings.Contains(hard.(ai.Explainer).Rationale().Rule, "bid tables") {
+		t.Errorf("bid %d on a lone Wizard: %s", bid, hard.(ai.Explainer).Rationale())
+	}
+}
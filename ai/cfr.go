@@ -0,0 +1,288 @@
+package ai
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// StrategyVersion is the version of the strategy files this build reads.
+const StrategyVersion = 1
+
+// MaxStrategyHand is the largest hand the strategy tables are computed for:
+// beyond it the game tree is too large for a near-equilibrium.
+const MaxStrategyHand = 3
+
+// Strategy is a strategy table computed by counterfactual regret
+// minimisation: for every information set of the small turns, the chance of
+// every abstract move. The probabilities are kept in 255ths, which is all the
+// precision a mixed strategy played a few times a game needs.
+type Strategy struct {
+	Version int
+	// the information sets, by the key InfoSet writes
+	Tables map[string][]uint8
+	// the tables computed, by players and hand size, and the iterations run
+	Iterations map[string]int
+}
+
+// NewStrategy returns an empty strategy table.
+func NewStrategy() *Strategy {
+	return &Strategy{Version: StrategyVersion, Tables: map[string][]uint8{}, Iterations: map[string]int{}}
+}
+
+// StrategyTable names the table of a number of players and a hand size.
+func StrategyTable(players, handSize int) string {
+	if handSize == 1 {
+		return fmt.Sprintf("%d players, 1 card", players)
+	}
+	return fmt.Sprintf("%d players, %d cards", players, handSize)
+}
+
+// Set keeps the probabilities of the moves of an information set.
+func (s *Strategy) Set(key string, probabilities []float64) {
+	quantized := make([]uint8, len(probabilities))
+	for i, p := range probabilities {
+		quantized[i] = uint8(min(255, max(0, p*255+0.5)))
+	}
+	s.Tables[key] = quantized
+}
+
+// Probabilities returns the chance of every move of an information set, nil
+// when the table doesn't know it.
+func (s *Strategy) Probabilities(key string) []float64 {
+	quantized, ok := s.Tables[key]
+	if !ok {
+		return nil
+	}
+	total := 0
+	for _, q := range quantized {
+		total += int(q)
+	}
+	probabilities := make([]float64, len(quantized))
+	for i, q := range quantized {
+		if total == 0 {
+			probabilities[i] = 1 / float64(len(quantized))
+		} else {
+			probabilities[i] = float64(q) / float64(total)
+		}
+	}
+	return probabilities
+}
+
+// LoadStrategy reads a strategy saved by Save.
+func LoadStrategy(path string) (*Strategy, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	s := &Strategy{}
+	if err := gob.NewDecoder(reader).Decode(s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Version != StrategyVersion {
+		return nil, fmt.Errorf("%s holds a strategy of version %d, this build reads version %d", path, s.Version, StrategyVersion)
+	}
+	return s, nil
+}
+
+// Save writes the strategy gzipped.
+func (s *Strategy) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(file)
+	if err := gob.NewEncoder(writer).Encode(s); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// InfoSet abstracts what the view's seat knows into the key of its
+// information set, with the moves open to it. Cards are told apart only by
+// what they are to the trick: W and J, t for a trump, l for the suit led and
+// o for any other suit, and the number in four bands (1-4, 5-8, 9-11 and
+// 12-13). A bid knows the seat's order and the sum of the bids before it; a
+// play knows the tricks still needed, how many cards the trick holds and the
+// one taking it, and which of the seat's cards would take it. The moves are the bids, or one card of
+// every kind in the hand: the cheapest that takes the trick, or the highest
+// that doesn't.
+func InfoSet(view *engine.PlayerView) (string, []engine.Action) {
+	n, left := view.NumPlayers(), len(view.Hand)
+	var b strings.Builder
+	if view.Bids[view.Seat] < 0 {
+		earlier := 0
+		for _, bid := range view.Bids {
+			earlier += max(0, bid)
+		}
+		fmt.Fprintf(&b, "bid %d/%d %d %s %s|%d", n, left, Position(view), trumpHeld(view.TrumpSuit), handKey(view.Hand, view.TrumpSuit, ""), earlier)
+		actions := make([]engine.Action, left+1)
+		for bid := range actions {
+			actions[bid] = engine.BidOf(bid)
+		}
+		return b.String(), actions
+	}
+
+	trick := &view.Trick
+	need := min(2, max(-1, view.Need(view.Seat)))
+	fmt.Fprintf(&b, "play %d/%d %d %s %d %s|", n, view.HandSize, left, trumpHeld(view.TrumpSuit), need, handKey(view.Hand, view.TrumpSuit, trick.Suit))
+	if highest := trick.Highest(); highest != nil {
+		fmt.Fprintf(&b, "%d %s", len(trick.Cards), cardKey(*highest, view.TrumpSuit, trick.Suit))
+	}
+
+	// One card of every kind, the kinds in the order of their keys
+	kinds := map[string]card.Card{}
+	for _, c := range trick.LegalPlays(view.Hand) {
+		wins := len(trick.Cards) == 0 || trick.Beats(c)
+		kind := cardKey(c, view.TrumpSuit, trick.Suit) + "-"
+		if wins {
+			kind = cardKey(c, view.TrumpSuit, trick.Suit) + "+"
+		}
+		held, ok := kinds[kind]
+		if !ok || (wins && strength(c, view.TrumpSuit) < strength(held, view.TrumpSuit)) || (!wins && strength(c, view.TrumpSuit) > strength(held, view.TrumpSuit)) {
+			kinds[kind] = c
+		}
+	}
+	keys := make([]string, 0, len(kinds))
+	for kind := range kinds {
+		keys = append(keys, kind)
+	}
+	slices.Sort(keys)
+	b.WriteString("|" + strings.Join(keys, ""))
+	actions := make([]engine.Action, len(keys))
+	for i, kind := range keys {
+		actions[i] = engine.PlayOf(kinds[kind])
+	}
+	return b.String(), actions
+}
+
+func trumpHeld(trump card.Symbol) string {
+	if trump == "" {
+		return "-"
+	}
+	return "t"
+}
+
+// handKey writes the hand in the abstraction, sorted.
+func handKey(hand []card.Card, trump, led card.Symbol) string {
+	keys := make([]string, len(hand))
+	for i, c := range hand {
+		keys[i] = cardKey(c, trump, led)
+	}
+	slices.Sort(keys)
+	return strings.Join(keys, "")
+}
+
+// cardKey writes a card in the abstraction: W, J or the suit and band, as in
+// t4 for a high trump.
+func cardKey(c card.Card, trump, led card.Symbol) string {
+	switch {
+	case c.IsWizard:
+		return "W"
+	case c.IsJoker:
+		return "J"
+	}
+	suit := "o"
+	switch c.Symbol {
+	case trump:
+		suit = "t"
+	case led:
+		suit = "l"
+	}
+	band := 1
+	switch {
+	case c.Number >= 12:
+		band = 4
+	case c.Number >= 9:
+		band = 3
+	case c.Number >= 5:
+		band = 2
+	}
+	return suit + strconv.Itoa(band)
+}
+
+// strength orders the cards within a kind.
+func strength(c card.Card, trump card.Symbol) int {
+	switch {
+	case c.IsWizard:
+		return 30
+	case c.IsJoker:
+		return 0
+	case c.Symbol == trump:
+		return 13 + c.Number
+	}
+	return c.Number
+}
+
+// CFRStrategy is the strategy the cfr level plays.
+var CFRStrategy *Strategy
+
+// CFRAgent bids and plays the small turns by a strategy table, drawing every
+// move by its probability, and the rest as the medium level does.
+type CFRAgent struct {
+	heuristic
+	Strategy *Strategy
+	rng      *rand.Rand
+}
+
+func NewCFRAgent(strategy *Strategy, personality Personality, seed int64) *CFRAgent {
+	return &CFRAgent{heuristic: heuristic{Personality: personality, counting: true}, Strategy: strategy, rng: rand.New(rand.NewSource(seed))}
+}
+
+// lookup draws the move of the view's information set, false when the
+// strategy doesn't cover it.
+func (a *CFRAgent) lookup(view *engine.PlayerView) (engine.Action, bool) {
+	if a.Strategy == nil || view.HandSize > MaxStrategyHand {
+		return engine.Action{}, false
+	}
+	key, actions := InfoSet(view)
+	probabilities := a.Strategy.Probabilities(key)
+	if len(probabilities) != len(actions) {
+		return engine.Action{}, false
+	}
+
+	pick := draw(probabilities, a.rng)
+	if a.explaining {
+		candidates := make([]Candidate, len(actions))
+		for i, action := range actions {
+			candidates[i] = Candidate{Move: Describe(action), Score: probabilities[i]}
+		}
+		a.rationale = &Rationale{
+			Decision:   Describe(actions[pick]),
+			Rule:       fmt.Sprintf("drawn from the equilibrium strategy of %s", StrategyTable(view.NumPlayers(), view.HandSize)),
+			Candidates: candidates,
+		}
+	}
+	return actions[pick], true
+}
+
+func (a *CFRAgent) Bid(view *engine.PlayerView) int {
+	if action, ok := a.lookup(view); ok {
+		return action.Bid
+	}
+	return a.heuristic.Bid(view)
+}
+
+func (a *CFRAgent) Play(view *engine.PlayerView) card.Card {
+	if action, ok := a.lookup(view); ok {
+		return action.Card
+	}
+	return a.heuristic.Play(view)
+}
@@ -0,0 +1,305 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// CloneVersion is the version of the clone files this build reads.
+const CloneVersion = 1
+
+// PlayFeatures names the features of a card the play policy of a clone
+// weighs, in the order of its weights:
+//
+//	wins          the card takes the trick as it stands, or leads it
+//	leading       the seat leads the trick
+//	strength      how high the card ranks, from 0 for a Joker to 1 for a Wizard
+//	wizard        the card is a Wizard
+//	joker         the card is a Joker
+//	trump         the card is a trump
+//	winsNeeded    wins, while the bid needs more tricks
+//	winsMade      wins, once the bid is made
+//	highNeeded    strength, while the bid needs more tricks
+//	highMade      strength, once the bid is made
+//	winsLast      wins, played last to the trick
+var PlayFeatures = []string{
+	"wins", "leading", "strength", "wizard", "joker", "trump",
+	"winsNeeded", "winsMade", "highNeeded", "highMade", "winsLast",
+}
+
+// playFeatures returns the features of playing c, in the order of
+// PlayFeatures.
+func playFeatures(view *engine.PlayerView, c card.Card) []float64 {
+	flag := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	leading := len(view.Trick.Cards) == 0
+	wins := flag(leading || view.Trick.Beats(c))
+	needed := flag(view.Need(view.Seat) > 0)
+	high := float64(strength(c, view.TrumpSuit)) / 30
+	return []float64{
+		wins,
+		flag(leading),
+		high,
+		flag(c.IsWizard),
+		flag(c.IsJoker),
+		flag(!c.IsWizard && !c.IsJoker && view.TrumpSuit != "" && c.Symbol == view.TrumpSuit),
+		wins * needed,
+		wins * (1 - needed),
+		high * needed,
+		high * (1 - needed),
+		wins * flag(len(view.Trick.Cards) == view.NumPlayers()-1),
+	}
+}
+
+// Clone imitates a player from the decisions the game log holds of them:
+// the bids are a regression of the player's own bids on the features of the
+// hand, drawn around the prediction as widely as the player strays from it,
+// and the cards a choice model over the legal ones. Drawing the decisions
+// rather than taking the likeliest keeps the player's habits, their mistakes
+// included.
+type Clone struct {
+	Version int    `json:"version"`
+	Player  string `json:"player"`
+	// the bids, with Spread the standard deviation of the player's bids
+	// around the prediction
+	Bid *BidModel `json:"bid"`
+	// the weights of PlayFeatures in the choice of a card
+	PlayFeatures []string  `json:"playFeatures"`
+	Weights      []float64 `json:"weights"`
+	// the decisions the clone was fitted on
+	Bids  int `json:"bids"`
+	Plays int `json:"plays"`
+}
+
+// PlaySample is a card played in a logged game, with what the seat knew.
+type PlaySample struct {
+	View engine.PlayerView
+	Card card.Card
+}
+
+// FitClone fits the clone of a player on their decisions: the bids as the
+// bid model does, the cards by maximum likelihood with a ridge penalty.
+func FitClone(player string, bids []BidSample, plays []PlaySample, ridge float64) (*Clone, error) {
+	// The target of the regression is the bid itself
+	samples := make([]BidSample, len(bids))
+	for i, s := range bids {
+		samples[i] = s
+		samples[i].Tricks = s.Bid
+	}
+	bid, err := FitBidModel(samples, ridge)
+	if err != nil {
+		return nil, fmt.Errorf("%s's bids: %w", player, err)
+	}
+	if len(plays) == 0 {
+		return nil, fmt.Errorf("%s played no card with a choice", player)
+	}
+
+	return &Clone{
+		Version:      CloneVersion,
+		Player:       player,
+		Bid:          bid,
+		PlayFeatures: slices.Clone(PlayFeatures),
+		Weights:      fitChoice(plays, ridge),
+		Bids:         len(bids),
+		Plays:        len(plays),
+	}, nil
+}
+
+// cloneEpochs and cloneRate are the steps of the gradient ascent of the
+// choice model and their size.
+const (
+	cloneEpochs = 300
+	cloneRate   = 1.0
+)
+
+// fitChoice fits the weights of the features of the cards played by
+// gradient ascent on the log-likelihood of the choices.
+func fitChoice(plays []PlaySample, ridge float64) []float64 {
+	type choice struct {
+		options [][]float64
+		chosen  int
+	}
+	choices := make([]choice, len(plays))
+	for i, p := range plays {
+		legal := distinctLegal(&p.View)
+		choices[i].options = make([][]float64, len(legal))
+		for j, c := range legal {
+			choices[i].options[j] = playFeatures(&p.View, c)
+		}
+		choices[i].chosen = slices.Index(legal, p.Card)
+	}
+
+	weights := make([]float64, len(PlayFeatures))
+	n := float64(len(choices))
+	for range cloneEpochs {
+		gradient := make([]float64, len(weights))
+		for _, c := range choices {
+			if c.chosen < 0 {
+				continue
+			}
+			probabilities := softmax(weights, c.options)
+			for k := range weights {
+				gradient[k] += c.options[c.chosen][k]
+				for j, x := range c.options {
+					gradient[k] -= probabilities[j] * x[k]
+				}
+			}
+		}
+		for k := range weights {
+			weights[k] += cloneRate * (gradient[k]/n - ridge*weights[k]/n)
+		}
+	}
+	return weights
+}
+
+// softmax is the chance of every option under the weights.
+func softmax(weights []float64, options [][]float64) []float64 {
+	utilities := make([]float64, len(options))
+	for j, x := range options {
+		for k, w := range weights {
+			utilities[j] += w * x[k]
+		}
+	}
+	top := slices.Max(utilities)
+	total := 0.0
+	for j := range utilities {
+		utilities[j] = math.Exp(utilities[j] - top)
+		total += utilities[j]
+	}
+	for j := range utilities {
+		utilities[j] /= total
+	}
+	return utilities
+}
+
+// distinctLegal returns the legal cards of the view, each once.
+func distinctLegal(view *engine.PlayerView) []card.Card {
+	var legal []card.Card
+	for _, c := range view.Trick.LegalPlays(view.Hand) {
+		if !slices.Contains(legal, c) {
+			legal = append(legal, c)
+		}
+	}
+	return legal
+}
+
+// BidChances returns the chance the clone bids every number.
+func (c *Clone) BidChances(view *engine.PlayerView) []float64 {
+	return c.Bid.distribution(view)
+}
+
+// PlayChances returns the distinct legal cards and the chance the clone
+// plays each.
+func (c *Clone) PlayChances(view *engine.PlayerView) ([]card.Card, []float64) {
+	legal := distinctLegal(view)
+	options := make([][]float64, len(legal))
+	for j, card := range legal {
+		options[j] = playFeatures(view, card)
+	}
+	return legal, softmax(c.Weights, options)
+}
+
+// LoadClone reads a clone saved by Save, checking it weighs the features
+// this build computes.
+func LoadClone(path string) (*Clone, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	clone := &Clone{}
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil, err
+	}
+	if clone.Version != CloneVersion {
+		return nil, fmt.Errorf("%s holds a clone of version %d, this build reads version %d", path, clone.Version, CloneVersion)
+	}
+	if clone.Bid == nil || !slices.Equal(clone.Bid.Features, BidFeatures) || !slices.Equal(clone.PlayFeatures, PlayFeatures) || len(clone.Weights) != len(PlayFeatures) {
+		return nil, fmt.Errorf("%s weighs other features than this build", path)
+	}
+	return clone, nil
+}
+
+func (c *Clone) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// CloneModel is the player the clone level imitates.
+var CloneModel *Clone
+
+// CloneAgent draws its bids and cards from a clone, and names the trump as
+// the medium level does. Without a clone it plays as the medium level.
+type CloneAgent struct {
+	heuristic
+	Clone *Clone
+	rng   *rand.Rand
+}
+
+func NewCloneAgent(clone *Clone, personality Personality, seed int64) *CloneAgent {
+	return &CloneAgent{heuristic: heuristic{Personality: personality, counting: true}, Clone: clone, rng: rand.New(rand.NewSource(seed))}
+}
+
+func (a *CloneAgent) Bid(view *engine.PlayerView) int {
+	if a.Clone == nil {
+		return a.heuristic.Bid(view)
+	}
+	chances := a.Clone.BidChances(view)
+	bid := draw(chances, a.rng)
+	if a.explaining {
+		candidates := make([]Candidate, len(chances))
+		for b, p := range chances {
+			candidates[b] = Candidate{Move: fmt.Sprintf("bid %d", b), Score: p}
+		}
+		a.rationale = &Rationale{
+			Decision:   fmt.Sprintf("bid %d", bid),
+			Rule:       fmt.Sprintf("drawn as %s bids, %.1f ± %.1f", a.Clone.Player, a.Clone.Bid.Predict(view), a.Clone.Bid.Spread),
+			Candidates: candidates,
+		}
+	}
+	return bid
+}
+
+func (a *CloneAgent) Play(view *engine.PlayerView) card.Card {
+	if a.Clone == nil {
+		return a.heuristic.Play(view)
+	}
+	legal, chances := a.Clone.PlayChances(view)
+	pick := legal[draw(chances, a.rng)]
+	if a.explaining {
+		candidates := make([]Candidate, len(legal))
+		for j, c := range legal {
+			candidates[j] = Candidate{Move: "play " + c.String(), Score: chances[j]}
+		}
+		a.rationale = &Rationale{
+			Decision:   "play " + pick.String(),
+			Rule:       "drawn as " + a.Clone.Player + " plays",
+			Candidates: candidates,
+		}
+	}
+	return pick
+}
+
+// draw picks an index by the chances.
+func draw(chances []float64, rng *rand.Rand) int {
+	x := rng.Float64()
+	for i, p := range chances {
+		if x < p {
+			return i
+		}
+		x -= p
+	}
+	return len(chances) - 1
+}
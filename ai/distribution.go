@@ -0,0 +1,225 @@
+package ai
+
+import (
+	"container/list"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"slices"
+	"sync"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// distributionSamples is the number of deals played out for a distribution.
+const distributionSamples = 500
+
+// distributionCacheSize bounds the distributions kept, the least recently
+// used being dropped first.
+const distributionCacheSize = 1024
+
+// distributions caches the distributions by hand shape.
+var distributions = newShapeCache(distributionCacheSize)
+
+// TrickDistribution returns the probability of taking k tricks with hand, for
+// k from 0 to len(hand). turnedUp is the trump card turned up, zero when none
+// was. position counts the seats bidding before this one, from the dealer's
+// left, and earlierBids, when known, holds their bids.
+//
+// The deal is played out many times over, the other cards dealt at random
+// and every seat, this one included, played by the medium level agent. The
+// result is taken to depend on the shape of the hand only, so it is cached
+// and computed on the representative hand of the shape: the symbols other
+// than trump are interchangeable, the numbers of a symbol only count by the
+// band they fall in, and a fixed seed per shape keeps the answer the same
+// from one call to the next.
+func TrickDistribution(hand []card.Card, turnedUp card.Card, trump card.Symbol, numPlayers, position int, earlierBids []int) ([]float64, error) {
+	switch {
+	case numPlayers < 2 || len(hand)*numPlayers > 60:
+		return nil, fmt.Errorf("can't deal %d cards to %d players", len(hand), numPlayers)
+	case position < 0 || position >= numPlayers:
+		return nil, fmt.Errorf("position %d is not a seat of %d players", position, numPlayers)
+	case earlierBids != nil && len(earlierBids) != position:
+		return nil, fmt.Errorf("%d bids made before position %d", len(earlierBids), position)
+	}
+
+	hand, turnedUp = shapeOf(hand, trump, turnedUp)
+	key := fmt.Sprintf("%v/%v/%s/%d/%d/%v", hand, turnedUp, trump, numPlayers, position, earlierBids)
+	if dist, ok := distributions.get(key); ok {
+		return slices.Clone(dist), nil
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	dist := simulateTricks(hand, turnedUp, trump, numPlayers, position, earlierBids, rand.New(rand.NewSource(int64(h.Sum64()))))
+	distributions.put(key, dist)
+	return slices.Clone(dist), nil
+}
+
+// shapeCache keeps the distributions of the shapes used last.
+type shapeCache struct {
+	sync.Mutex
+	size   int
+	byKey  map[string]*list.Element
+	recent *list.List // of *shapeEntry, the most recently used first
+}
+
+type shapeEntry struct {
+	key  string
+	dist []float64
+}
+
+func newShapeCache(size int) *shapeCache {
+	return &shapeCache{size: size, byKey: make(map[string]*list.Element), recent: list.New()}
+}
+
+func (c *shapeCache) get(key string) ([]float64, bool) {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.byKey[key]
+	if !ok {
+		return nil, false
+	}
+	c.recent.MoveToFront(e)
+	return e.Value.(*shapeEntry).dist, true
+}
+
+func (c *shapeCache) put(key string, dist []float64) {
+	c.Lock()
+	defer c.Unlock()
+	if e, ok := c.byKey[key]; ok {
+		c.recent.MoveToFront(e)
+		return
+	}
+	c.byKey[key] = c.recent.PushFront(&shapeEntry{key: key, dist: dist})
+	if c.recent.Len() > c.size {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.byKey, oldest.Value.(*shapeEntry).key)
+	}
+}
+
+func simulateTricks(hand []card.Card, turnedUp card.Card, trump card.Symbol, numPlayers, position int, earlierBids []int, rng *rand.Rand) []float64 {
+	// The dealer sits last, so the seat is its position. The turned-up card
+	// is seen, so it is dealt to no one.
+	view := engine.PlayerView{
+		Seat:      position,
+		Dealer:    numPlayers - 1,
+		HandSize:  len(hand),
+		Hand:      hand,
+		Trump:     turnedUp,
+		TrumpSuit: trump,
+		Bids:      make([]int, numPlayers),
+		Won:       make([]int, numPlayers),
+		Trick:     engine.NewTrick(trump),
+	}
+	for seat := range view.Bids {
+		view.Bids[seat] = -1
+	}
+	copy(view.Bids, earlierBids)
+
+	reference := &heuristic{Personality: Balanced, counting: true}
+	tracker := TrackerFromView(&view)
+	counts := make([]float64, len(hand)+1)
+	for range distributionSamples {
+		state := deal(&view, tracker, rng)
+		if earlierBids == nil {
+			// The seats before this one bid in the simulation too
+			state.ToAct = 0
+		}
+
+		for state.Phase != engine.Done {
+			seatView := state.View(state.ToAct)
+			if state.Phase == engine.Bidding {
+				state.Apply(engine.BidOf(reference.Bid(&seatView)))
+			} else {
+				state.Apply(engine.PlayOf(reference.Play(&seatView)))
+			}
+		}
+		counts[state.Won[position]]++
+	}
+
+	for k := range counts {
+		counts[k] /= distributionSamples
+	}
+	return counts
+}
+
+// bands are the lowest numbers of the bands of cards of about the same
+// strength in a symbol, from the strongest.
+var bands = []int{11, 7, 1}
+
+func band(number int) int {
+	for i, lowest := range bands {
+		if number >= lowest {
+			return i
+		}
+	}
+	return len(bands) - 1
+}
+
+// bandTop is the highest number of a band.
+func bandTop(b int) int {
+	if b == 0 {
+		return 13
+	}
+	return bands[b-1] - 1
+}
+
+// shapeOf returns the representative of the shape of hand and the turned-up
+// card: the same Wizards and Jokers and, in every symbol, as many cards in
+// every band, numbered down from the top of the band, the turned-up card
+// first. The symbols other than trump are given out in a fixed order of
+// their counts.
+func shapeOf(hand []card.Card, trump card.Symbol, turnedUp card.Card) ([]card.Card, card.Card) {
+	counts := make(map[card.Symbol][]int)
+	for _, symbol := range card.Symbols {
+		counts[symbol] = make([]int, len(bands))
+	}
+	var shaped []card.Card
+	for _, c := range hand {
+		if c.IsWizard || c.IsJoker {
+			shaped = append(shaped, c)
+		} else {
+			counts[c.Symbol][band(c.Number)]++
+		}
+	}
+
+	var others []card.Symbol
+	for _, symbol := range card.Symbols {
+		if symbol != trump {
+			others = append(others, symbol)
+		}
+	}
+	sorted := slices.Clone(others)
+	slices.SortStableFunc(sorted, func(a, b card.Symbol) int { return slices.Compare(counts[b], counts[a]) })
+	renamed := map[card.Symbol]card.Symbol{trump: trump}
+	for i, symbol := range sorted {
+		renamed[symbol] = others[i]
+	}
+
+	next := make(map[card.Symbol][]int)
+	for _, symbol := range card.Symbols {
+		next[symbol] = make([]int, len(bands))
+		for b := range bands {
+			next[symbol][b] = bandTop(b)
+		}
+	}
+	number := func(symbol card.Symbol, n int) card.Card {
+		b := band(n)
+		c := card.Card{Number: next[symbol][b], Symbol: symbol}
+		next[symbol][b]--
+		return c
+	}
+	if !turnedUp.IsWizard && !turnedUp.IsJoker && turnedUp != (card.Card{}) {
+		turnedUp = number(turnedUp.Symbol, turnedUp.Number)
+	}
+	for _, symbol := range append([]card.Symbol{trump}, sorted...) {
+		for b, count := range counts[symbol] {
+			for range count {
+				shaped = append(shaped, number(renamed[symbol], bandTop(b)))
+			}
+		}
+	}
+	return shaped, turnedUp
+}
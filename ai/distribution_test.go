@@ -0,0 +1,59 @@
+package ai_test
+
+import (
+	"math"
+	"testing"
+	"wizard/ai"
+	"wizard/card"
+)
+
+// TestTrickDistribution checks that the distribution is one and only
+// depends on the shape of the hand
+func TestTrickDistribution(t *testing.T) {
+	hand := []card.Card{
+		{IsWizard: true, Number: -1},
+		{Number: 12, Symbol: card.Blue},
+		{Number: 3, Symbol: card.Red},
+		{Number: 9, Symbol: card.Green},
+	}
+	up := card.Card{Number: 5, Symbol: card.Blue}
+	dist, err := ai.TrickDistribution(hand, up, card.Blue, 4, 1, []int{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dist) != len(hand)+1 {
+		t.Fatalf("%d probabilities for %d cards", len(dist), len(hand))
+	}
+	total := 0.0
+	for _, p := range dist {
+		total += p
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("probabilities add up to %.3f", total)
+	}
+
+	// Swapping the symbols other than trump changes nothing
+	swapped := append([]card.Card(nil), hand...)
+	swapped[2].Symbol, swapped[3].Symbol = card.Green, card.Red
+	again, _ := ai.TrickDistribution(swapped, up, card.Blue, 4, 1, []int{2})
+	for k := range dist {
+		if again[k] != dist[k] {
+			t.Fatalf("P(%d) is %.3f, %.3f with the symbols swapped", k, dist[k], again[k])
+		}
+	}
+
+	// Cards of a symbol in the same band are alike: Blue 11 to 13 are high
+	// cards, 7 to 10 middle ones
+	alike := append([]card.Card(nil), hand...)
+	alike[1].Number, alike[3].Number = 11, 7
+	again, _ = ai.TrickDistribution(alike, card.Card{Number: 6, Symbol: card.Blue}, card.Blue, 4, 1, []int{2})
+	for k := range dist {
+		if again[k] != dist[k] {
+			t.Fatalf("P(%d) is %.3f, %.3f for a hand of the same shape", k, dist[k], again[k])
+		}
+	}
+
+	if _, err := ai.TrickDistribution(hand, up, card.Blue, 4, 1, []int{2, 1}); err == nil {
+		t.Error("two earlier bids for the second seat are accepted")
+	}
+}
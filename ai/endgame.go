@@ -0,0 +1,149 @@
+package ai
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"wizard/card"
+	"wizard/engine"
+	"wizard/solver"
+)
+
+// Endgame bounds when a simulating agent stops sampling deals and solves the
+// rest of the turn exactly: every deal of the unseen cards that agrees with
+// the voids the opponents have shown is played out by minimax, the seat
+// playing for its bid and the others against it, and the card with the best
+// average score over the deals is played.
+type Endgame struct {
+	Tricks  int // the most tricks left to solve, 0 never to solve
+	Unknown int // the most cards the seat hasn't seen, in hands or deck
+}
+
+// endgameDeals bounds the deals enumerated, whatever the number of unknown
+// cards, so that a decision never takes long.
+const endgameDeals = 1000
+
+// ExpertEndgame is when the expert level solves the turn exactly.
+var ExpertEndgame = Endgame{Tricks: 4, Unknown: 12}
+
+// SolveEndgame scores every distinct legal card of the view by its average
+// minimax score over every consistent deal. It returns no deals when the
+// position lies outside the endgame.
+func SolveEndgame(view *engine.PlayerView, endgame Endgame) (cards []card.Card, scores []float64, deals int) {
+	if view.TricksLeft() > endgame.Tricks || slices.Contains(view.Bids, -1) {
+		return nil, nil, 0
+	}
+	unseen := view.Unseen()
+	if len(unseen) > endgame.Unknown {
+		return nil, nil, 0
+	}
+	hands, ok := enumerateDeals(view, TrackerFromView(view), unseen)
+	if !ok || len(hands) == 0 {
+		return nil, nil, 0
+	}
+
+	for _, c := range view.Trick.LegalPlays(view.Hand) {
+		if !slices.Contains(cards, c) {
+			cards = append(cards, c)
+		}
+	}
+	totals := make([][]int, len(hands))
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				deal := engine.StateFromView(view, hands[i])
+				totals[i] = make([]int, len(cards))
+				for j, c := range cards {
+					state := deal.Clone()
+					state.Apply(engine.PlayOf(c))
+					totals[i][j] = solver.Score(state, view.Seat)
+				}
+			}
+		}()
+	}
+	for i := range hands {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	scores = make([]float64, len(cards))
+	for _, deal := range totals {
+		for j, total := range deal {
+			scores[j] += float64(total) / float64(len(hands))
+		}
+	}
+	return cards, scores, len(hands)
+}
+
+// enumerateDeals lists every way to deal the unseen cards to the opponents,
+// as many to each as it still holds and none of a symbol it has shown it
+// lacks. It gives up past endgameDeals.
+func enumerateDeals(view *engine.PlayerView, tracker *Tracker, unseen []card.Card) ([][][]card.Card, bool) {
+	var opponents []int
+	for seat := range view.NumPlayers() {
+		if seat != view.Seat {
+			opponents = append(opponents, seat)
+		}
+	}
+
+	var deals [][][]card.Card
+	hands := make([][]card.Card, view.NumPlayers())
+	hands[view.Seat] = view.Hand
+	used := make([]bool, len(unseen))
+
+	// fill deals the cards from the index from on to opponents[k], then
+	// moves on to the next opponent
+	var fill func(k, from int) bool
+	fill = func(k, from int) bool {
+		if k == len(opponents) {
+			if len(deals) == endgameDeals {
+				return false
+			}
+			deal := make([][]card.Card, len(hands))
+			for seat, hand := range hands {
+				deal[seat] = slices.Clone(hand)
+			}
+			deals = append(deals, deal)
+			return true
+		}
+		seat := opponents[k]
+		if len(hands[seat]) == view.CardsLeft(seat) {
+			return fill(k+1, 0)
+		}
+		for i := from; i < len(unseen); i++ {
+			if used[i] || tracker.excluded(seat, unseen[i]) {
+				continue
+			}
+			used[i] = true
+			hands[seat] = append(hands[seat], unseen[i])
+			ok := fill(k, i+1)
+			hands[seat] = hands[seat][:len(hands[seat])-1]
+			used[i] = false
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return deals, fill(0, 0)
+}
+
+// explainEndgame keeps the average score of every card over the deals.
+func (s *simulation) explainEndgame(view *engine.PlayerView, cards []card.Card, scores []float64, deals int, pick int) {
+	candidates := make([]Candidate, len(cards))
+	for i, c := range cards {
+		candidates[i] = Candidate{Move: "play " + c.String(), Score: scores[i]}
+	}
+	s.rationale = &Rationale{
+		Decision:   "play " + cards[pick].String(),
+		Rule:       fmt.Sprintf("best average score over all %d deals, solved exactly", deals),
+		Cards:      s.contributions(view, s.knowledge(view), 1),
+		Candidates: candidates,
+	}
+}
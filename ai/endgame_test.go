@@ -0,0 +1,39 @@
+package ai_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// TestEndgame checks the endgame of the expert against every deal of the
+// last tricks
+func TestEndgame(t *testing.T) {
+	// The last turn of three players deals the whole deck: with two tricks
+	// left only the four cards of the opponents are unseen
+	rng := rand.New(rand.NewSource(3))
+	turnDeck := deck.InitDeck()
+	turnDeck.ShuffleWith(rng)
+	state := engine.NewState(3, 0, 20, turnDeck)
+	medium := ai.New(ai.Medium, ai.Balanced, 1)
+	for state.Phase != engine.Playing || len(state.Hands[state.ToAct]) > 2 {
+		view := state.View(state.ToAct)
+		if _, _, deals := ai.SolveEndgame(&view, ai.ExpertEndgame); deals > 0 && len(view.Hand) > ai.ExpertEndgame.Tricks {
+			t.Fatalf("solved with %d tricks left", len(view.Hand))
+		}
+		state.Apply(arena.Decide(medium, state))
+	}
+	view := state.View(state.ToAct)
+	cards, scores, deals := ai.SolveEndgame(&view, ai.ExpertEndgame)
+	if deals == 0 || deals > 6 || len(cards) != len(scores) || len(cards) == 0 {
+		t.Fatalf("endgame of %d cards solved over %d deals: %v %v", len(view.Unseen()), deals, cards, scores)
+	}
+	pick := cards[slices.Index(scores, slices.Max(scores))]
+	if c := ai.New(ai.Expert, ai.Balanced, 1).Play(&view); scores[slices.Index(cards, c)] != slices.Max(scores) {
+		t.Errorf("expert played %s, the endgame solves to %s", c, pick)
+	}
+}
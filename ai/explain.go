@@ -0,0 +1,183 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// Rationale tells why an agent took a decision: the rule it followed, what
+// it expected of every card in its hand and the moves it weighed.
+type Rationale struct {
+	Decision string `json:"decision"` // as in "bid 3", "play W" or "trump Elf"
+	Rule     string `json:"rule"`
+	// the tricks every card of the hand is expected to take
+	Cards []Contribution `json:"cards,omitempty"`
+	// the moves weighed, with the score each was given
+	Candidates []Candidate `json:"candidates,omitempty"`
+}
+
+type Contribution struct {
+	Card   card.Card `json:"card"`
+	Tricks float64   `json:"tricks"`
+}
+
+// Candidate is a move an agent weighed. Scores compare within a rationale
+// only: expected points for a bid, the chance of taking the trick for the
+// card of a heuristic seat, average points for a simulating one, or its
+// chance to win the game in the final turns.
+type Candidate struct {
+	Move   string  `json:"move"`
+	Score  float64 `json:"score"`
+	Visits int     `json:"visits,omitempty"` // times a tree search tried the move
+}
+
+// Explainer is implemented by agents that can tell why they took their
+// decisions.
+type Explainer interface {
+	// Explain has the agent keep the rationale of its decisions from now on
+	Explain()
+	// Rationale returns the rationale of the last decision, nil when none
+	// was kept
+	Rationale() *Rationale
+}
+
+// String lays the rationale out for the console, one line per part.
+func (r *Rationale) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %s: %s", r.Decision, r.Rule)
+	if len(r.Cards) > 0 {
+		b.WriteString("\n  cards:")
+		for _, c := range r.Cards {
+			fmt.Fprintf(&b, " %s %.2f", c.Card, c.Tricks)
+		}
+	}
+	if len(r.Candidates) > 0 {
+		b.WriteString("\n  weighed:")
+		for _, c := range r.Candidates {
+			fmt.Fprintf(&b, " %s %+.2f", c.Move, c.Score)
+			if c.Visits > 0 {
+				fmt.Fprintf(&b, " (%d)", c.Visits)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Describe writes an action as a rationale's decision.
+func Describe(action engine.Action) string {
+	switch action.Kind {
+	case engine.BidAction:
+		return fmt.Sprintf("bid %d", action.Bid)
+	case engine.TrumpAction:
+		return fmt.Sprintf("trump %s", action.Suit)
+	}
+	return fmt.Sprintf("play %s", action.Card)
+}
+
+func (h *heuristic) Explain() {
+	h.explaining = true
+}
+
+func (h *heuristic) Rationale() *Rationale {
+	return h.rationale
+}
+
+// contributions is the chance every card of the hand takes a trick.
+func (h *heuristic) contributions(view *engine.PlayerView, k knowledge, factor float64) []Contribution {
+	cards := make([]Contribution, len(view.Hand))
+	for i, c := range view.Hand {
+		cards[i] = Contribution{Card: c, Tricks: math.Min(1, cardWinProbability(c, view.TrumpSuit, k)*factor)}
+	}
+	return cards
+}
+
+// bidCandidates gives every bid its expected score over a distribution of
+// the tricks taken.
+func bidCandidates(dist []float64) []Candidate {
+	candidates := make([]Candidate, len(dist))
+	for bid := range dist {
+		score := 0.0
+		for tricks, q := range dist {
+			score += q * float64(engine.Score(bid, tricks))
+		}
+		candidates[bid] = Candidate{Move: fmt.Sprintf("bid %d", bid), Score: score}
+	}
+	return candidates
+}
+
+func (h *heuristic) explainBid(view *engine.PlayerView, k knowledge, bid int) {
+	factor := 1.0
+	if h.modeling {
+		factor = h.tableFactor(view) * h.BidFactor * h.tuning().BidScale
+	}
+	cards := h.contributions(view, k, factor)
+	probabilities := make([]float64, len(cards))
+	expected := 0.0
+	for i, c := range cards {
+		probabilities[i] = c.Tricks
+		expected += c.Tricks
+	}
+
+	rule := fmt.Sprintf("%.1f tricks expected, times a bid factor of %.2f, %s rounding", expected, h.BidFactor*h.tuning().BidScale, h.Rounding)
+	if h.modeling {
+		rule = fmt.Sprintf("%.1f tricks expected after reading the table, best expected score", expected)
+	}
+	h.rationale = &Rationale{
+		Decision:   fmt.Sprintf("bid %d", bid),
+		Rule:       rule,
+		Cards:      cards,
+		Candidates: bidCandidates(tricksDistribution(probabilities)),
+	}
+}
+
+// explainPlay scores every legal card by its chance to take the trick.
+func (h *heuristic) explainPlay(view *engine.PlayerView, legal []card.Card, pick card.Card, rule string) {
+	k := h.knowledge(view)
+	candidates := make([]Candidate, 0, len(legal))
+	for _, c := range legal {
+		chance := 0.0
+		switch {
+		case len(view.Trick.Cards) == 0:
+			chance = cardWinProbability(c, view.TrumpSuit, k)
+		case c.IsWizard && view.Trick.Beats(c):
+			chance = 1
+		case view.Trick.Beats(c):
+			chance = h.holdProbability(view, c, k)
+		}
+		candidates = append(candidates, Candidate{Move: "play " + c.String(), Score: chance})
+	}
+
+	switch need := view.Need(view.Seat); {
+	case need == 1:
+		rule = "need 1 more trick, " + rule
+	case need > 1:
+		rule = fmt.Sprintf("need %d more tricks, %s", need, rule)
+	default:
+		rule = "bid made, " + rule
+	}
+	h.rationale = &Rationale{
+		Decision:   "play " + pick.String(),
+		Rule:       rule,
+		Cards:      h.contributions(view, k, 1),
+		Candidates: candidates,
+	}
+}
+
+func (h *heuristic) explainTrump(view *engine.PlayerView, options []TrumpOption) {
+	candidates := make([]Candidate, len(options))
+	for i, option := range options {
+		candidates[i] = Candidate{Move: "trump " + string(option.Symbol), Score: option.Score}
+	}
+	best := options[0]
+	trumped := *view
+	trumped.TrumpSuit = best.Symbol
+	h.rationale = &Rationale{
+		Decision:   "trump " + string(best.Symbol),
+		Rule:       fmt.Sprintf("best expected score: %.1f tricks expected, bidding %d", best.Tricks, best.Bid),
+		Cards:      h.contributions(&trumped, handKnowledge(view.Hand, view.NumPlayers()), 1),
+		Candidates: candidates,
+	}
+}
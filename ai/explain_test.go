@@ -0,0 +1,36 @@
+package ai_test
+
+import (
+	"math/rand"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// TestExplainedDecisions checks that every level tells the decision it took
+// and why
+func TestExplainedDecisions(t *testing.T) {
+	agents := []ai.Agent{ai.New(ai.Easy, ai.Balanced, 1), ai.New(ai.Medium, ai.Balanced, 2), ai.New(ai.Hard, ai.Balanced, 3),
+		ai.NewISMCTS(ai.Balanced, ai.TreeSearch{Search: ai.Search{Iterations: 50}}, 4)}
+	for _, agent := range agents {
+		agent.(ai.Explainer).Explain()
+	}
+
+	turnDeck := deck.InitDeck()
+	turnDeck.ShuffleWith(rand.New(rand.NewSource(9)))
+	state := engine.NewState(len(agents), 0, 4, turnDeck)
+	for state.Phase != engine.Done {
+		agent := agents[state.ToAct]
+		action := arena.Decide(agent, state)
+		r := agent.(ai.Explainer).Rationale()
+		if r == nil || r.Decision != ai.Describe(action) || r.Rule == "" {
+			t.Fatalf("%T took %s and explained %+v", agent, ai.Describe(action), r)
+		}
+		if action.Kind != engine.TrumpAction && len(r.Candidates) > 1 && len(r.Cards) != len(state.Hands[state.ToAct]) {
+			t.Errorf("%T weighed %d cards of a hand of %d", agent, len(r.Cards), len(state.Hands[state.ToAct]))
+		}
+		state.Apply(action)
+	}
+}
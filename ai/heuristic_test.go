@@ -0,0 +1,145 @@
+package ai_test
+
+import (
+	"testing"
+	"wizard/ai"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// TestSelectCard checks the card the medium level picks: the lowest winner
+// when it needs the trick, the highest loser when it doesn't, and a Wizard
+// kept back until the bid can't do without it.
+func TestSelectCard(t *testing.T) {
+	wizard := card.Card{IsWizard: true, Number: -1}
+	joker := card.Card{IsJoker: true, Number: -1}
+	green := func(n int) card.Card { return card.Card{Number: n, Symbol: card.Green} }
+	blue := func(n int) card.Card { return card.Card{Number: n, Symbol: card.Blue} }
+	red := func(n int) card.Card { return card.Card{Number: n, Symbol: card.Red} }
+
+	tests := []struct {
+		name   string
+		hand   []card.Card
+		played []card.Card // by the seats before, from seat 0
+		bids   []int
+		// the tricks played before, every one led by seat 0
+		history [][]card.Card
+		noTrump bool
+		want    card.Card
+	}{
+		{
+			name:   "last to play wins with the lowest winner",
+			hand:   []card.Card{green(12), green(7), wizard},
+			played: []card.Card{green(5), green(2)},
+			bids:   []int{0, 0, 1},
+			want:   green(7),
+		},
+		{
+			name:   "blocks a seat still needing tricks with the highest winner",
+			hand:   []card.Card{green(12), green(7), blue(3)},
+			played: []card.Card{green(5)},
+			bids:   []int{0, 1, 1},
+			want:   green(12),
+		},
+		{
+			name:   "trumps a suit it can't follow",
+			hand:   []card.Card{red(4), blue(3), blue(9)},
+			played: []card.Card{green(13), green(2)},
+			bids:   []int{0, 0, 1},
+			want:   red(4),
+		},
+		{
+			name:   "ducks with the highest card that still loses",
+			hand:   []card.Card{green(4), green(8), green(11)},
+			played: []card.Card{green(9), green(1)},
+			bids:   []int{1, 0, 0},
+			want:   green(8),
+		},
+		{
+			name:   "dumps a Joker first",
+			hand:   []card.Card{green(4), joker, blue(13)},
+			played: []card.Card{green(9), green(1)},
+			bids:   []int{1, 0, 0},
+			want:   joker,
+		},
+		{
+			name: "leads the lowest card not needing a trick",
+			hand: []card.Card{blue(11), green(3), wizard},
+			bids: []int{0, 1, 1},
+			want: green(3),
+		},
+		{
+			name:    "every card wins and nothing left overtakes: gets rid of the highest",
+			hand:    []card.Card{green(13), green(12)},
+			played:  []card.Card{green(5)},
+			bids:    []int{2, 0, 0},
+			history: [][]card.Card{{wizard, wizard, wizard}, {wizard, joker, joker}},
+			noTrump: true,
+			want:    green(13),
+		},
+		{
+			name:    "every card wins and the seat after can't follow: gets rid of the highest",
+			hand:    []card.Card{green(8), green(9)},
+			played:  []card.Card{green(5)},
+			bids:    []int{3, 0, 0},
+			history: [][]card.Card{{wizard, wizard, wizard}, {wizard, joker, joker}, {green(2), green(1), blue(3)}},
+			noTrump: true,
+			want:    green(9),
+		},
+		{
+			name:    "every card wins: plays low to be overtaken",
+			hand:    []card.Card{green(13), green(12)},
+			played:  []card.Card{green(5)},
+			bids:    []int{0, 0, 0},
+			noTrump: true,
+			want:    green(12),
+		},
+		{
+			name:   "saves the Wizard for a later trick",
+			hand:   []card.Card{wizard, green(2), blue(3)},
+			played: []card.Card{green(13), green(10)},
+			bids:   []int{1, 0, 1},
+			want:   green(2),
+		},
+		{
+			name:   "spends the Wizard when every trick is needed",
+			hand:   []card.Card{wizard, green(2), blue(3)},
+			played: []card.Card{green(13), green(10)},
+			bids:   []int{0, 0, 3},
+			want:   wizard,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			seat := len(test.played)
+			trump := card.Red
+			if test.noTrump {
+				trump = ""
+			}
+			view := engine.PlayerView{
+				Seat:      seat,
+				Dealer:    2,
+				HandSize:  len(test.hand) + len(test.history),
+				Hand:      test.hand,
+				TrumpSuit: trump,
+				Bids:      test.bids,
+				Won:       make([]int, 3),
+				Trick:     engine.NewTrick(trump),
+			}
+			for _, cards := range test.history {
+				trick := engine.NewTrick(trump)
+				for s, c := range cards {
+					trick.Play(s, c)
+				}
+				view.History = append(view.History, trick)
+				view.Won[trick.Winner()]++
+			}
+			for s, c := range test.played {
+				view.Trick.Play(s, c)
+			}
+			if got := ai.New(ai.Medium, ai.Balanced, 1).Play(&view); got != test.want {
+				t.Errorf("played %s, want %s", got, test.want)
+			}
+		})
+	}
+}
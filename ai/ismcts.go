@@ -0,0 +1,254 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// TreeSearch configures an ISMCTS agent.
+type TreeSearch struct {
+	Search
+	// Exploration is the UCB constant: higher tries the less visited
+	// actions more often
+	Exploration float64
+	// Reuse keeps the tree grown for one decision for the next ones of the
+	// same turn
+	Reuse bool
+}
+
+// DefaultTreeSearch bounds the ismcts level's search.
+var DefaultTreeSearch = TreeSearch{Search: Search{Iterations: 1000}, Exploration: 0.7, Reuse: true}
+
+// ISMCTS is single observer Information Set Monte Carlo Tree Search. The
+// tree is grown over the actions seen from the seat's side of the table:
+// every iteration deals the unseen cards anew, consistently with the known
+// voids, walks the tree down by the actions legal in that deal, choosing by
+// UCB among the children available, adds one node and plays the rest of the
+// turn out with the rollout policy. Each node counts the score of the seat
+// that took its action, so the opponents are searched as playing for
+// themselves. Unlike the simulation agent, one tree is shared by all the
+// deals, so a decision can't depend on knowing the hidden cards.
+type ISMCTS struct {
+	heuristic
+	config  TreeSearch
+	rng     *rand.Rand
+	rollout heuristic
+
+	// the tree kept between the decisions of a turn
+	root    *node
+	turn    [3]int // seat, hand size and dealer the tree is for
+	actions int    // public actions taken before the root
+}
+
+// node is reached by one action from its parent.
+type node struct {
+	seat     int // the seat that took the action
+	action   engine.Action
+	parent   *node
+	children []*node
+	visits   int
+	avail    int // times the action was legal when the parent was visited
+	reward   float64
+}
+
+// NewISMCTS creates a tree searching agent.
+func NewISMCTS(personality Personality, config TreeSearch, seed int64) *ISMCTS {
+	return &ISMCTS{
+		heuristic: heuristic{Personality: personality, counting: true, modeling: true},
+		config:    config,
+		rng:       rand.New(rand.NewSource(seed)),
+		rollout:   heuristic{Personality: Balanced},
+	}
+}
+
+func (m *ISMCTS) Bid(view *engine.PlayerView) int {
+	return m.BidContext(context.Background(), view)
+}
+
+func (m *ISMCTS) Play(view *engine.PlayerView) card.Card {
+	return m.PlayContext(context.Background(), view)
+}
+
+// BidContext searches until the iterations or the time run out or ctx is done.
+func (m *ISMCTS) BidContext(ctx context.Context, view *engine.PlayerView) int {
+	return m.search(ctx, view).Bid
+}
+
+// PlayContext searches until the iterations or the time run out or ctx is done.
+func (m *ISMCTS) PlayContext(ctx context.Context, view *engine.PlayerView) card.Card {
+	legal := view.Trick.LegalPlays(view.Hand)
+	if len(legal) == 1 {
+		if m.explaining {
+			m.rationale = &Rationale{Decision: "play " + legal[0].String(), Rule: "the only legal card"}
+		}
+		return legal[0]
+	}
+	return m.search(ctx, view).Card
+}
+
+func (m *ISMCTS) search(ctx context.Context, view *engine.PlayerView) engine.Action {
+	if m.config.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.config.Budget)
+		defer cancel()
+	}
+
+	root := m.reuse(view)
+	tracker := TrackerFromView(view)
+	iterations := 0
+	for ; iterations == 0 || iterations < m.config.Iterations && ctx.Err() == nil; iterations++ {
+		m.iterate(root, deal(view, tracker, m.rng), view.HandSize)
+	}
+
+	var pick *node
+	for _, child := range root.children {
+		if pick == nil || child.visits > pick.visits {
+			pick = child
+		}
+	}
+	if m.explaining {
+		m.explainTree(view, root, pick, iterations)
+	}
+	return pick.action
+}
+
+// explainTree keeps the average score and the visits of every move tried
+// from the root.
+func (m *ISMCTS) explainTree(view *engine.PlayerView, root, pick *node, iterations int) {
+	span := float64(20 + 20*view.HandSize)
+	candidates := make([]Candidate, len(root.children))
+	for i, child := range root.children {
+		candidates[i] = Candidate{
+			Move:   Describe(child.action),
+			Score:  child.reward/float64(child.visits)*span - float64(10*view.HandSize),
+			Visits: child.visits,
+		}
+	}
+	m.rationale = &Rationale{
+		Decision:   Describe(pick.action),
+		Rule:       fmt.Sprintf("most visited move after %d iterations", iterations),
+		Cards:      m.contributions(view, m.knowledge(view), 1),
+		Candidates: candidates,
+	}
+}
+
+// reuse finds the node of the current decision in the tree of the turn, or
+// starts a new tree.
+func (m *ISMCTS) reuse(view *engine.PlayerView) *node {
+	actions := publicActions(view)
+	turn := [3]int{view.Seat, view.HandSize, view.Dealer}
+
+	root := m.root
+	if !m.config.Reuse || root == nil || m.turn != turn || m.actions > len(actions) {
+		root = nil
+	} else {
+		for _, action := range actions[m.actions:] {
+			if root = root.child(action); root == nil {
+				break
+			}
+		}
+	}
+	if root == nil {
+		root = &node{seat: -1}
+	}
+
+	root.parent = nil
+	m.root, m.turn, m.actions = root, turn, len(actions)
+	return root
+}
+
+// iterate runs one deal through the tree.
+func (m *ISMCTS) iterate(root *node, state *engine.State, handSize int) {
+	n := root
+	for state.Phase != engine.Done {
+		legal := state.LegalActions()
+		var untried []engine.Action
+		var available []*node
+		for _, action := range legal {
+			if child := n.child(action); child != nil {
+				child.avail++
+				available = append(available, child)
+			} else {
+				untried = append(untried, action)
+			}
+		}
+
+		if len(untried) > 0 {
+			action := untried[m.rng.Intn(len(untried))]
+			child := &node{seat: state.ToAct, action: action, parent: n}
+			n.children = append(n.children, child)
+			child.avail++
+			state.Apply(action)
+			n = child
+			break
+		}
+
+		n = m.choose(available)
+		state.Apply(n.action)
+	}
+
+	for state.Phase != engine.Done {
+		view := state.View(state.ToAct)
+		if state.Phase == engine.Bidding {
+			state.Apply(engine.BidOf(m.rollout.Bid(&view)))
+		} else {
+			state.Apply(engine.PlayOf(m.rollout.Play(&view)))
+		}
+	}
+
+	// Scores run from -10 per card to 20 + 10 per card: bring them to [0, 1]
+	scores := state.Scores()
+	span := float64(20 + 20*handSize)
+	for ; n != nil; n = n.parent {
+		n.visits++
+		if n.seat >= 0 {
+			n.reward += float64(scores[n.seat]+10*handSize) / span
+		}
+	}
+}
+
+// choose picks the child with the best upper confidence bound, counting
+// only the visits in which its action was available.
+func (m *ISMCTS) choose(children []*node) *node {
+	var pick *node
+	best := math.Inf(-1)
+	for _, child := range children {
+		ucb := child.reward/float64(child.visits) +
+			m.config.Exploration*math.Sqrt(math.Log(float64(child.avail))/float64(child.visits))
+		if ucb > best {
+			pick, best = child, ucb
+		}
+	}
+	return pick
+}
+
+func (n *node) child(action engine.Action) *node {
+	for _, child := range n.children {
+		if child.action == action {
+			return child
+		}
+	}
+	return nil
+}
+
+// publicActions lists the turn's decisions so far, in the order they were
+// taken: the predictions from the dealer's left, then the cards.
+func publicActions(view *engine.PlayerView) []engine.Action {
+	var actions []engine.Action
+	for i := range view.NumPlayers() {
+		if bid := view.Bids[(view.Dealer+1+i)%view.NumPlayers()]; bid >= 0 {
+			actions = append(actions, engine.BidOf(bid))
+		}
+	}
+	for _, trick := range append(slices.Clone(view.History), view.Trick) {
+		for _, c := range trick.Cards {
+			actions = append(actions, engine.PlayOf(c))
+		}
+	}
+	return actions
+}
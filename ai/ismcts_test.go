@@ -0,0 +1,54 @@
+package ai_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// TestISMCTSCancelled checks that a cancelled search still answers legally
+func TestISMCTSCancelled(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	turnDeck := deck.InitDeck()
+	rng.Shuffle(len(turnDeck), func(i, j int) { turnDeck[i], turnDeck[j] = turnDeck[j], turnDeck[i] })
+	state := engine.NewState(3, 0, 5, turnDeck)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	agent := ai.NewISMCTS(ai.Balanced, ai.DefaultTreeSearch, 1)
+	for state.Phase != engine.Done {
+		view := state.View(state.ToAct)
+		var action engine.Action
+		switch state.Phase {
+		case engine.Choosing:
+			action = arena.Decide(agent, state)
+		case engine.Bidding:
+			action = engine.BidOf(agent.BidContext(ctx, &view))
+		case engine.Playing:
+			action = engine.PlayOf(agent.PlayContext(ctx, &view))
+		}
+		if err := state.Apply(action); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestISMCTSLevel plays the ismcts level against the simulating levels
+func TestISMCTSLevel(t *testing.T) {
+	level, err := ai.LevelByName("ismcts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	agents := []ai.Agent{ai.New(level, ai.Aggressive, 1), ai.New(ai.Hard, ai.Balanced, 2), ai.New(ai.Expert, ai.Balanced, 3)}
+	if _, ok := agents[0].(*ai.ISMCTS); !ok {
+		t.Fatalf("the ismcts level plays as %T", agents[0])
+	}
+	state := arena.Turn(agents, 0, 4, rand.New(rand.NewSource(5)), nil)
+	if state.Phase != engine.Done || state.Bids[0] < 0 {
+		t.Errorf("the turn ended in phase %d, the ismcts seat bidding %d", state.Phase, state.Bids[0])
+	}
+}
@@ -0,0 +1,356 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// BidModelVersion is the version of the bid model files this build reads.
+const BidModelVersion = 1
+
+// BidFeatures names the features of a hand the bid model weighs, in the
+// order of its coefficients, so that the model file can be read elsewhere:
+//
+//	bias           1
+//	handSize       cards in the hand
+//	dealt          share of the deck dealt: handSize * players / 60
+//	players        seats at the table
+//	position       order of the seat's bid, 0 for the first and 1 for the dealer
+//	wizards        Wizards held
+//	jokers         Jokers held
+//	trumps         trumps held
+//	trumpHeight    sum of number / 13 over the trumps
+//	highTrumps     trumps numbered 11 or more
+//	kings          off-suit 13s (every 13 with no trump)
+//	queens         off-suit 12s
+//	offSuitHeight  sum of (number / 13)³ over the off-suit cards
+//	voids          off-suit symbols the hand lacks, when there is a trump
+//	crowdedHigh    (kings + wizards) * (players - 3)
+var BidFeatures = []string{
+	"bias", "handSize", "dealt", "players", "position", "wizards", "jokers",
+	"trumps", "trumpHeight", "highTrumps", "kings", "queens", "offSuitHeight",
+	"voids", "crowdedHigh",
+}
+
+// bidFeatures returns the features of a hand, in the order of BidFeatures.
+func bidFeatures(hand []card.Card, trump card.Symbol, numPlayers, position int) []float64 {
+	var wizards, jokers, trumps, trumpHeight, highTrumps, kings, queens, offSuitHeight float64
+	held := map[card.Symbol]bool{}
+	for _, c := range hand {
+		switch {
+		case c.IsWizard:
+			wizards++
+		case c.IsJoker:
+			jokers++
+		case trump != "" && c.Symbol == trump:
+			trumps++
+			trumpHeight += float64(c.Number) / 13
+			if c.Number >= 11 {
+				highTrumps++
+			}
+		default:
+			held[c.Symbol] = true
+			switch c.Number {
+			case 13:
+				kings++
+			case 12:
+				queens++
+			}
+			offSuitHeight += math.Pow(float64(c.Number)/13, 3)
+		}
+	}
+
+	voids := 0.0
+	if trump != "" {
+		for _, symbol := range card.Symbols {
+			if symbol != trump && !held[symbol] {
+				voids++
+			}
+		}
+	}
+
+	n := float64(numPlayers)
+	return []float64{
+		1,
+		float64(len(hand)),
+		float64(len(hand)) * n / 60,
+		n,
+		float64(position) / math.Max(1, n-1),
+		wizards,
+		jokers,
+		trumps,
+		trumpHeight,
+		highTrumps,
+		kings,
+		queens,
+		offSuitHeight,
+		voids,
+		(kings + wizards) * (n - 3),
+	}
+}
+
+// BidModel is a linear regression of the tricks a seat takes on the features
+// of its hand, fitted on logged games.
+type BidModel struct {
+	Version      int       `json:"version"`
+	Features     []string  `json:"features"`
+	Coefficients []float64 `json:"coefficients"`
+	// Spread is the standard deviation of the tricks taken around the
+	// prediction, on the turns the model was fitted on
+	Spread  float64 `json:"spread"`
+	Samples int     `json:"samples"`
+}
+
+// BidSample is a prediction made in a logged game: what the seat knew when it
+// bid, and the tricks it went on to take.
+type BidSample struct {
+	View   engine.PlayerView
+	Bid    int
+	Tricks int
+}
+
+// Position is the order of the seat's bid: 0 bids first, the dealer last.
+func Position(view *engine.PlayerView) int {
+	n := view.NumPlayers()
+	return (view.Seat - view.Dealer - 1 + n) % n
+}
+
+// BidSamples returns the predictions of every complete turn of a game log,
+// as observed by engine.Everyone.
+func BidSamples(events []engine.Event) []BidSample {
+	var (
+		samples []BidSample
+		turn    []BidSample
+		hands   [][]card.Card
+		trump   card.Card
+		suit    card.Symbol
+		bids    []int
+	)
+
+	for _, e := range events {
+		switch e.Kind {
+		case engine.DealEvent:
+			if e.Seat == 0 {
+				hands, turn = make([][]card.Card, e.Players), nil
+			}
+			hands[e.Seat] = e.Hand
+		case engine.TrumpEvent:
+			trump, suit = e.Card, e.Suit
+			bids = make([]int, e.Players)
+			for seat := range bids {
+				bids[seat] = -1
+			}
+		case engine.BidEvent:
+			if hands == nil || hands[e.Seat] == nil {
+				continue
+			}
+			turn = append(turn, BidSample{
+				View: engine.PlayerView{
+					Seat:      e.Seat,
+					Dealer:    e.Dealer,
+					HandSize:  e.HandSize,
+					Hand:      hands[e.Seat],
+					Trump:     trump,
+					TrumpSuit: suit,
+					Bids:      slices.Clone(bids),
+					Won:       make([]int, e.Players),
+					Trick:     engine.NewTrick(suit),
+				},
+				Bid: e.Bid,
+			})
+			bids[e.Seat] = e.Bid
+		case engine.ScoreEvent:
+			for i := range turn {
+				if turn[i].View.Seat == e.Seat {
+					turn[i].Tricks = e.Tricks
+					samples = append(samples, turn[i])
+				}
+			}
+		}
+	}
+	return samples
+}
+
+// FitBidModel fits the model on the samples by least squares, with a ridge
+// penalty on every coefficient but the bias.
+func FitBidModel(samples []BidSample, ridge float64) (*BidModel, error) {
+	size := len(BidFeatures)
+	if len(samples) < size {
+		return nil, fmt.Errorf("%d turns are too few to fit %d features", len(samples), size)
+	}
+
+	// The normal equations: (XᵀX + ridge I) w = Xᵀy
+	a := make([][]float64, size)
+	for i := range a {
+		a[i] = make([]float64, size+1)
+		if i > 0 {
+			a[i][i] = ridge
+		}
+	}
+	for _, s := range samples {
+		x := s.features()
+		for i := range size {
+			for j := range size {
+				a[i][j] += x[i] * x[j]
+			}
+			a[i][size] += x[i] * float64(s.Tricks)
+		}
+	}
+	coefficients, err := solveLinear(a)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &BidModel{
+		Version:      BidModelVersion,
+		Features:     slices.Clone(BidFeatures),
+		Coefficients: coefficients,
+		Samples:      len(samples),
+	}
+	squares := 0.0
+	for _, s := range samples {
+		residual := float64(s.Tricks) - model.Predict(&s.View)
+		squares += residual * residual
+	}
+	model.Spread = math.Sqrt(squares / float64(len(samples)))
+	return model, nil
+}
+
+func (s *BidSample) features() []float64 {
+	return bidFeatures(s.View.Hand, s.View.TrumpSuit, s.View.NumPlayers(), Position(&s.View))
+}
+
+// solveLinear solves the augmented system by Gaussian elimination with
+// partial pivoting.
+func solveLinear(a [][]float64) ([]float64, error) {
+	size := len(a)
+	for col := range size {
+		pivot := col
+		for row := col + 1; row < size; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, fmt.Errorf("the features of the turns are not independent")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for row := col + 1; row < size; row++ {
+			f := a[row][col] / a[col][col]
+			for k := col; k <= size; k++ {
+				a[row][k] -= f * a[col][k]
+			}
+		}
+	}
+
+	x := make([]float64, size)
+	for row := size - 1; row >= 0; row-- {
+		sum := a[row][size]
+		for k := row + 1; k < size; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, nil
+}
+
+// Predict returns the tricks the seat is expected to take.
+func (m *BidModel) Predict(view *engine.PlayerView) float64 {
+	x := bidFeatures(view.Hand, view.TrumpSuit, view.NumPlayers(), Position(view))
+	tricks := 0.0
+	for i, w := range m.Coefficients {
+		tricks += w * x[i]
+	}
+	return math.Max(0, math.Min(float64(len(view.Hand)), tricks))
+}
+
+// Bid spreads the tricks normally around the prediction and bids the number
+// with the best expected score.
+func (m *BidModel) Bid(view *engine.PlayerView) int {
+	bid, _ := bestExpected(m.distribution(view))
+	return bid
+}
+
+// distribution is the chance of taking every number of tricks.
+func (m *BidModel) distribution(view *engine.PlayerView) []float64 {
+	mean, spread := m.Predict(view), math.Max(m.Spread, 0.3)
+	cumulative := func(x float64) float64 {
+		return 0.5 * (1 + math.Erf((x-mean)/(spread*math.Sqrt2)))
+	}
+
+	dist := make([]float64, len(view.Hand)+1)
+	for tricks := range dist {
+		low, high := cumulative(float64(tricks)-0.5), cumulative(float64(tricks)+0.5)
+		switch tricks {
+		case 0:
+			low = 0
+		case len(view.Hand):
+			high = 1
+		}
+		dist[tricks] = high - low
+	}
+	return dist
+}
+
+// LoadBidModel reads a model saved by Save, checking that it weighs the
+// features this build computes.
+func LoadBidModel(path string) (*BidModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	model := &BidModel{}
+	if err := json.Unmarshal(data, model); err != nil {
+		return nil, err
+	}
+	if model.Version != BidModelVersion {
+		return nil, fmt.Errorf("%s holds a bid model of version %d, this build reads version %d", path, model.Version, BidModelVersion)
+	}
+	if !slices.Equal(model.Features, BidFeatures) || len(model.Coefficients) != len(BidFeatures) {
+		return nil, fmt.Errorf("%s weighs other features than %v", path, BidFeatures)
+	}
+	return model, nil
+}
+
+func (m *BidModel) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// BidderModel is the model the learned level bids with.
+var BidderModel *BidModel
+
+// LearnedBidder bids with a model fitted on logged games and plays as the
+// medium level does. Without a model it bids as the medium level too.
+type LearnedBidder struct {
+	heuristic
+	Model *BidModel
+}
+
+func NewLearnedBidder(model *BidModel, personality Personality) *LearnedBidder {
+	return &LearnedBidder{heuristic: heuristic{Personality: personality, counting: true}, Model: model}
+}
+
+func (l *LearnedBidder) Bid(view *engine.PlayerView) int {
+	if l.Model == nil {
+		return l.heuristic.Bid(view)
+	}
+	bid := l.Model.Bid(view)
+	if l.explaining {
+		l.rationale = &Rationale{
+			Decision:   fmt.Sprintf("bid %d", bid),
+			Rule:       fmt.Sprintf("the model predicts %.1f ± %.1f tricks, best expected score", l.Model.Predict(view), l.Model.Spread),
+			Cards:      l.contributions(view, l.knowledge(view), 1),
+			Candidates: bidCandidates(l.Model.distribution(view)),
+		}
+	}
+	return bid
+}
@@ -0,0 +1,61 @@
+package ai_test
+
+import (
+	"math/rand"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/card"
+	"wizard/engine"
+	"wizard/gamelog"
+)
+
+// TestLearnedBidder checks that logged games survive the trip through the
+// game log, and that a model fitted on them bids sensibly
+func TestLearnedBidder(t *testing.T) {
+	path := t.TempDir() + "/games.jsonl"
+	rng := rand.New(rand.NewSource(3))
+	for range 20 {
+		agents := []ai.Agent{ai.New(ai.Medium, ai.Balanced, 1), ai.New(ai.Medium, ai.Conservative, 2), ai.New(ai.Medium, ai.Aggressive, 3)}
+		result := arena.Play(agents, 0, rng)
+		if err := gamelog.Append(path, gamelog.Game{Players: []string{"A", "B", "C"}, Events: result.Events}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	games, err := gamelog.Read(path)
+	if err != nil || len(games) != 20 {
+		t.Fatalf("read %d games from the log: %v", len(games), err)
+	}
+
+	var samples []ai.BidSample
+	for _, game := range games {
+		samples = append(samples, ai.BidSamples(game.Events)...)
+	}
+	if len(samples) != 20*20*3 {
+		t.Fatalf("%d bids read from the log, want %d", len(samples), 20*20*3)
+	}
+	for _, s := range samples[:60] {
+		if len(s.View.Hand) != s.View.HandSize || s.Tricks < 0 || s.Tricks > s.View.HandSize {
+			t.Fatalf("inconsistent sample %+v", s)
+		}
+	}
+
+	model, err := ai.FitBidModel(samples, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := model.Save(t.TempDir() + "/bidder.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	strong := engine.PlayerView{Seat: 1, Dealer: 0, HandSize: 3, TrumpSuit: card.Red, Bids: []int{-1, -1, -1}, Won: make([]int, 3),
+		Hand: []card.Card{{IsWizard: true, Number: -1}, {IsWizard: true, Number: -1}, {Number: 13, Symbol: card.Red}}}
+	weak := strong
+	weak.Hand = []card.Card{{IsJoker: true, Number: -1}, {Number: 2, Symbol: card.Blue}, {Number: 3, Symbol: card.Green}}
+	if bid := ai.NewLearnedBidder(model, ai.Balanced).Bid(&strong); bid < 2 {
+		t.Errorf("bid %d on two Wizards and the top trump", bid)
+	}
+	if bid := ai.NewLearnedBidder(model, ai.Balanced).Bid(&weak); bid != 0 {
+		t.Errorf("bid %d on a Joker and two low cards", bid)
+	}
+}
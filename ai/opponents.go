@@ -0,0 +1,185 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// Weight of the prior in the opponents' tendencies: with few turns recorded,
+// a tendency stays close to what is expected of an unknown player.
+const (
+	priorTurns = 4.0
+	priorPlays = 10.0
+)
+
+// OpponentProfile is what was learned of a player over the games: how far
+// off its predictions run, how early it spends its Wizards and how often it
+// risks a trick it doesn't want.
+type OpponentProfile struct {
+	Name string `json:"name"`
+	// Bids keeps the predictions by hand size
+	Bids map[int]*BidRecord `json:"bids"`
+	// Wizards counts the Wizards played, EarlyWizards those played in the
+	// first half of a turn
+	Wizards      int `json:"wizards"`
+	EarlyWizards int `json:"earlyWizards"`
+	// SafePlays counts the cards played once the prediction was made,
+	// RiskyPlays those of them that were taking the trick when played
+	SafePlays  int `json:"safePlays"`
+	RiskyPlays int `json:"riskyPlays"`
+}
+
+type BidRecord struct {
+	Turns int `json:"turns"`
+	Over  int `json:"over"`  // turns taking fewer tricks than predicted
+	Under int `json:"under"` // turns taking more tricks than predicted
+	Error int `json:"error"` // tricks taken minus tricks predicted, summed over the turns
+}
+
+// Bias is the tricks the player is expected to take beyond its prediction
+// with handSize cards: negative for a habitual overbidder.
+func (p *OpponentProfile) Bias(handSize int) float64 {
+	if p == nil || p.Bids[handSize] == nil {
+		return 0
+	}
+	record := p.Bids[handSize]
+	return float64(record.Error) / (float64(record.Turns) + priorTurns)
+}
+
+// WizardDumping is the share of its Wizards the player spends in the first
+// half of a turn.
+func (p *OpponentProfile) WizardDumping() float64 {
+	if p == nil {
+		return 0.5
+	}
+	return (float64(p.EarlyWizards) + 0.5*priorPlays) / (float64(p.Wizards) + priorPlays)
+}
+
+// Risk is how often the player, once it made its prediction, still plays a
+// card taking the trick.
+func (p *OpponentProfile) Risk() float64 {
+	if p == nil {
+		return 0.3
+	}
+	return (float64(p.RiskyPlays) + 0.3*priorPlays) / (float64(p.SafePlays) + priorPlays)
+}
+
+// OpponentBook holds the profiles by player name and lives in a JSON file.
+type OpponentBook struct {
+	Profiles map[string]*OpponentProfile `json:"profiles"`
+}
+
+func NewOpponentBook() *OpponentBook {
+	return &OpponentBook{Profiles: make(map[string]*OpponentProfile)}
+}
+
+// LoadOpponentBook reads the profiles from path; a missing file is an empty book.
+func LoadOpponentBook(path string) (*OpponentBook, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewOpponentBook(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	book := NewOpponentBook()
+	if err := json.Unmarshal(data, book); err != nil {
+		return nil, err
+	}
+	if book.Profiles == nil {
+		book.Profiles = make(map[string]*OpponentProfile)
+	}
+	return book, nil
+}
+
+func (b *OpponentBook) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Profile returns the profile of the player named name, nil when unknown.
+func (b *OpponentBook) Profile(name string) *OpponentProfile {
+	return b.Profiles[name]
+}
+
+func (b *OpponentBook) profile(name string) *OpponentProfile {
+	p := b.Profiles[name]
+	if p == nil {
+		p = &OpponentProfile{Name: name, Bids: make(map[int]*BidRecord)}
+		b.Profiles[name] = p
+	}
+	return p
+}
+
+// Record adds a game to the profiles. names holds the player of every seat
+// and events is the game's log, as observed by engine.Everyone.
+func (b *OpponentBook) Record(names []string, events []engine.Event) {
+	var (
+		trump  card.Symbol
+		trick  engine.Trick
+		played int
+		bids   []int
+		won    []int
+	)
+
+	for _, e := range events {
+		switch e.Kind {
+		case engine.TrumpEvent:
+			// Every turn reveals its trump, even when there is none
+			trump, played = e.Suit, 0
+			trick = engine.NewTrick(trump)
+			bids, won = make([]int, e.Players), make([]int, e.Players)
+		case engine.BidEvent:
+			bids[e.Seat] = e.Bid
+		case engine.PlayEvent:
+			p := b.profile(names[e.Seat])
+			if e.Card.IsWizard {
+				p.Wizards++
+				if played/len(names) < e.HandSize/2 {
+					p.EarlyWizards++
+				}
+			}
+			if won[e.Seat] >= bids[e.Seat] {
+				p.SafePlays++
+				if trick.Beats(e.Card) {
+					p.RiskyPlays++
+				}
+			}
+			trick.Play(e.Seat, e.Card)
+			played++
+		case engine.TrickEvent:
+			won[e.Seat]++
+			trick = engine.NewTrick(trump)
+		case engine.ScoreEvent:
+			p := b.profile(names[e.Seat])
+			record := p.Bids[e.HandSize]
+			if record == nil {
+				record = &BidRecord{}
+				p.Bids[e.HandSize] = record
+			}
+			record.Turns++
+			record.Error += e.Tricks - e.Bid
+			switch {
+			case e.Tricks < e.Bid:
+				record.Over++
+			case e.Tricks > e.Bid:
+				record.Under++
+			}
+		}
+	}
+}
+
+// Reader is implemented by agents that adjust to the habits of the players
+// around the table.
+type Reader interface {
+	// Read gives the profile of the player of every seat, nil when unknown
+	Read(opponents []*OpponentProfile)
+}
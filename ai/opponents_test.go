@@ -0,0 +1,55 @@
+package ai_test
+
+import (
+	"math/rand"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// TestOpponentBook checks that a game's log updates the profiles and that
+// they survive a save and a load
+func TestOpponentBook(t *testing.T) {
+	names := []string{"Ann", "Bob", "Cid"}
+	var events []engine.Event
+	for handSize := 1; handSize <= 5; handSize++ {
+		turnDeck := deck.InitDeck()
+		rand.New(rand.NewSource(int64(handSize))).Shuffle(len(turnDeck), func(i, j int) { turnDeck[i], turnDeck[j] = turnDeck[j], turnDeck[i] })
+		state := engine.NewState(len(names), handSize%len(names), handSize, turnDeck)
+		state.Observe(engine.Everyone, func(e engine.Event) { events = append(events, e) })
+		agent := ai.New(ai.Medium, ai.Aggressive, 1)
+		for state.Phase != engine.Done {
+			state.Apply(arena.Decide(agent, state))
+		}
+	}
+
+	book := ai.NewOpponentBook()
+	book.Record(names, events)
+	path := t.TempDir() + "/opponents.json"
+	if err := book.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ai.LoadOpponentBook(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range names {
+		profile := loaded.Profile(name)
+		if profile == nil {
+			t.Fatalf("no profile for %s", name)
+		}
+		turns := 0
+		for _, record := range profile.Bids {
+			turns += record.Turns
+		}
+		if turns != 5 {
+			t.Errorf("%s has %d turns recorded, want 5", name, turns)
+		}
+		if profile.RiskyPlays > profile.SafePlays || profile.EarlyWizards > profile.Wizards {
+			t.Errorf("%s has inconsistent counts: %+v", name, profile)
+		}
+	}
+}
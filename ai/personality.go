@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type Rounding string
+
+const (
+	Floor Rounding = "floor"
+	Round Rounding = "round"
+	Ceil  Rounding = "ceil"
+)
+
+func (r Rounding) apply(x float64) float64 {
+	switch r {
+	case Floor:
+		return math.Floor(x)
+	case Ceil:
+		return math.Ceil(x)
+	}
+	return math.Round(x)
+}
+
+// Personality is the playing style of an AI seat
+type Personality struct {
+	Name string
+	// bid = Rounding(expectedTricks * BidFactor)
+	BidFactor float64
+	Rounding  Rounding
+	// RiskAppetite (0..1) is how readily the seat plays a winner that the
+	// players still to act may overtake, rather than its safest winner
+	RiskAppetite float64
+	// WizardEagerness (0..1) is how willing the seat is to spend its Wizards
+	// early instead of saving them for the tricks that matter
+	WizardEagerness float64
+}
+
+// AI Personality Types, as in the guide
+var (
+	Conservative = Personality{Name: "conservative", BidFactor: 0.9, Rounding: Floor, RiskAppetite: 0.25, WizardEagerness: 0.2}
+	Balanced     = Personality{Name: "balanced", BidFactor: 1.0, Rounding: Round, RiskAppetite: 0.5, WizardEagerness: 0.5}
+	Aggressive   = Personality{Name: "aggressive", BidFactor: 1.1, Rounding: Ceil, RiskAppetite: 0.8, WizardEagerness: 0.8}
+)
+
+var Personalities = []Personality{Conservative, Balanced, Aggressive}
+
+// PersonalityByName looks a personality up by name; an empty name is Balanced.
+func PersonalityByName(name string) (Personality, error) {
+	if name == "" {
+		return Balanced, nil
+	}
+	for _, p := range Personalities {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+	return Personality{}, fmt.Errorf("unknown AI profile '%s'", name)
+}
+
+// PersonalityFor is PersonalityByName falling back to Balanced.
+func PersonalityFor(name string) Personality {
+	p, err := PersonalityByName(name)
+	if err != nil {
+		return Balanced
+	}
+	return p
+}
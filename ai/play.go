@@ -0,0 +1,448 @@
+package ai
+
+import (
+	"math"
+	"slices"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// heuristic plays the guide's selectCard strategy in a given personality.
+// Card counting makes it the medium level; reading the opponents' predictions
+// guides the simulating levels.
+type heuristic struct {
+	Personality
+	counting bool // remember the cards played this turn
+	modeling bool // read the opponents' predictions
+	tracker  *Tracker
+	// what is known of the players at the table from earlier games, by seat
+	opponents []*OpponentProfile
+	// the magic numbers, DefaultWeights when nil
+	weights *Weights
+	// keep the rationale of the decisions
+	explaining bool
+	rationale  *Rationale
+}
+
+// Observe follows the turn, so a counting seat knows which cards have gone.
+func (h *heuristic) Observe(e engine.Event) {
+	if !h.counting {
+		return
+	}
+	if e.Kind == engine.DealEvent && (h.tracker == nil || h.tracker.Seat() != e.Seat) {
+		h.tracker = NewTracker(e.Seat)
+	}
+	if h.tracker != nil {
+		h.tracker.Observe(e)
+	}
+}
+
+// Read takes the profiles of the players at the table.
+func (h *heuristic) Read(opponents []*OpponentProfile) {
+	h.opponents = opponents
+}
+
+func (h *heuristic) tuning() Weights {
+	if h.weights == nil {
+		return DefaultWeights
+	}
+	return *h.weights
+}
+
+// opponent returns the profile of seat, nil when unknown.
+func (h *heuristic) opponent(seat int) *OpponentProfile {
+	if seat >= len(h.opponents) {
+		return nil
+	}
+	return h.opponents[seat]
+}
+
+// Bid predicts how many tricks the seat will win this turn.
+//
+//	bid = Rounding(expectedTricks * BidFactor)
+func (h *heuristic) Bid(view *engine.PlayerView) int {
+	k := h.knowledge(view)
+	var bid int
+	if h.modeling {
+		bid = h.bestBid(view, k)
+	} else {
+		expected := estimateTricks(view.Hand, view.TrumpSuit, k)
+		bid = clampBid(int(h.Rounding.apply(expected*h.BidFactor*h.tuning().BidScale)), len(view.Hand))
+	}
+	if h.explaining {
+		h.explainBid(view, k, bid)
+	}
+	return bid
+}
+
+// bestBid turns the win probability of every card into a distribution of
+// the tricks taken and bids the number with the best expected score.
+func (h *heuristic) bestBid(view *engine.PlayerView, k knowledge) int {
+	factor := h.tableFactor(view) * h.BidFactor * h.tuning().BidScale
+	probabilities := make([]float64, len(view.Hand))
+	for i, c := range view.Hand {
+		probabilities[i] = math.Min(1, cardWinProbability(c, view.TrumpSuit, k)*factor)
+	}
+	bid, _ := bestExpected(tricksDistribution(probabilities))
+	return bid
+}
+
+// tricksDistribution returns the probability of taking k tricks, for k from
+// 0 to len(probabilities), when every card wins with its own probability.
+func tricksDistribution(probabilities []float64) []float64 {
+	dist := []float64{1}
+	for _, p := range probabilities {
+		next := make([]float64, len(dist)+1)
+		for tricks, q := range dist {
+			next[tricks] += q * (1 - p)
+			next[tricks+1] += q * p
+		}
+		dist = next
+	}
+	return dist
+}
+
+// bestExpected returns the bid with the best expected score over a
+// distribution of the tricks taken, and that score.
+func bestExpected(dist []float64) (int, float64) {
+	bid, bestScore := 0, math.Inf(-1)
+	for b := range dist {
+		score := 0.0
+		for tricks, q := range dist {
+			score += q * float64(engine.Score(b, tricks))
+		}
+		if score > bestScore {
+			bid, bestScore = b, score
+		}
+	}
+	return bid, bestScore
+}
+
+// tableFactor shades the expected tricks down when the seats that already
+// bid claim more than their share of the tricks, and up when they claim less.
+// A known overbidder's claim is discounted.
+func (h *heuristic) tableFactor(view *engine.PlayerView) float64 {
+	claimed, bidders := 0.0, 0
+	for seat, bid := range view.Bids {
+		if bid >= 0 {
+			claimed += math.Max(0, float64(bid)+h.opponent(seat).Bias(view.HandSize))
+			bidders++
+		}
+	}
+	if bidders == 0 {
+		return 1
+	}
+	share := float64(view.HandSize*bidders) / float64(view.NumPlayers())
+	excess := (claimed - share) / float64(view.HandSize)
+	return math.Max(0.5, math.Min(1.5, 1-excess*h.tuning().TableShading))
+}
+
+func (h *heuristic) knowledge(view *engine.PlayerView) knowledge {
+	var k knowledge
+	switch {
+	case !h.counting:
+		k = handKnowledge(view.Hand, view.NumPlayers())
+	case h.tracker != nil && h.tracker.Ready() && len(h.tracker.Hand()) == len(view.Hand):
+		k = h.tracker.knowledge()
+	default:
+		// Without the events, count from what the view shows of the turn
+		k = countedKnowledge(view)
+	}
+	k.weights = h.tuning()
+	return k
+}
+
+// Play picks the card to play to the current trick. It only ever returns one
+// of the legal follow-suit plays.
+//
+//	if tricksNeeded > 0 {
+//	    return playToWin(trick, hand)
+//	}
+//	return playToLose(trick, hand)
+func (h *heuristic) Play(view *engine.PlayerView) card.Card {
+	legal := view.Trick.LegalPlays(view.Hand)
+
+	var pick card.Card
+	var rule string
+	if need := view.Need(view.Seat); need > 0 {
+		pick, rule = h.playToWin(view, legal, need)
+	} else {
+		pick, rule = h.playToLose(view, legal)
+	}
+	if h.explaining {
+		h.explainPlay(view, legal, pick, rule)
+	}
+	return pick
+}
+
+// playToWin plays the lowest card that wins, keeping Wizards for the tricks
+// that matter. When opponents still needing tricks play after us, it plays
+// the highest winner instead, to block them. It returns the rule followed.
+func (h *heuristic) playToWin(view *engine.PlayerView, legal []card.Card, need int) (card.Card, string) {
+	trump := view.TrumpSuit
+	wizards := countWizards(view.Hand)
+	// Every remaining trick is needed, or the Wizards alone won't make the
+	// bid; eager seats spend them sooner
+	wizardMatters := need >= view.TricksLeft() ||
+		float64(need) > float64(wizards)*h.tuning().WizardReserve*(1-h.WizardEagerness)
+
+	if len(view.Trick.Cards) == 0 {
+		if wizard, ok := find(legal, isWizard); ok && wizardMatters {
+			return wizard, "lead a Wizard the bid can't do without"
+		}
+		// Cash a card nobody can beat any more, otherwise lead with the
+		// strongest card to force opponents to use high cards
+		if h.counting {
+			k := h.knowledge(view)
+			sure := slices.DeleteFunc(withoutWizards(legal), func(c card.Card) bool { return !k.sure(c, trump) })
+			if len(sure) > 0 {
+				return lowest(sure, trump, sure), "cash a card nobody can beat"
+			}
+		}
+		return highest(withoutWizards(legal), trump, legal), "lead the strongest card"
+	}
+
+	winners, losers := split(legal, &view.Trick)
+	if normal := withoutWizards(winners); len(normal) > 0 {
+		if blocking(view) {
+			return highest(normal, trump, normal), "highest winner, blocking a seat that still needs tricks"
+		}
+		return h.safeWinner(view, normal), "lowest winner likely to hold"
+	}
+	if len(winners) > 0 && wizardMatters {
+		return winners[0], "win with a Wizard"
+	}
+
+	return lowest(withoutWizards(losers), trump, legal), "no winner, lowest card"
+}
+
+// playToLose plays the highest card that still loses, dumping Jokers first.
+// It returns the rule followed.
+func (h *heuristic) playToLose(view *engine.PlayerView, legal []card.Card) (card.Card, string) {
+	trump := view.TrumpSuit
+
+	if joker, ok := find(legal, isJoker); ok {
+		return joker, "dump a Joker"
+	}
+
+	if len(view.Trick.Cards) == 0 {
+		// A lead nothing left can beat takes the trick, however low
+		cards := withoutWizards(legal)
+		if beatable := slices.DeleteFunc(slices.Clone(cards), func(c card.Card) bool { return !h.overtakable(view, c) }); len(beatable) > 0 {
+			cards = beatable
+		}
+		return lowest(cards, trump, legal), "lead the lowest card"
+	}
+
+	winners, losers := split(legal, &view.Trick)
+	if len(losers) > 0 {
+		return highest(losers, trump, losers), "highest card that still loses"
+	}
+
+	// Every card wins: the last seat gets rid of its most dangerous card,
+	// the others play low and hope to be overtaken
+	if len(view.StillToPlay()) == 0 {
+		return highest(withoutWizards(winners), trump, winners), "every card wins, last to play: get rid of the most dangerous"
+	}
+	low := lowest(withoutWizards(winners), trump, winners)
+	if !h.overtakable(view, low) {
+		return highest(withoutWizards(winners), trump, winners), "every card wins and nothing left can overtake: get rid of the most dangerous"
+	}
+	return low, "every card wins: play low and hope to be overtaken"
+}
+
+// counts returns the seat's count of the turn, replayed from the view when
+// the seat didn't follow the events, nil when the seat doesn't count cards.
+func (h *heuristic) counts(view *engine.PlayerView) *Tracker {
+	switch {
+	case !h.counting:
+		return nil
+	case h.tracker != nil && h.tracker.Ready() && len(h.tracker.Hand()) == len(view.Hand):
+		return h.tracker
+	}
+	return TrackerFromView(view)
+}
+
+// overtakable reports whether a seat still to play could take the trick
+// from c once c takes it or leads it: with a Wizard, a higher card of its
+// symbol or, for a card off the trump, a trump, none of which the seat has
+// shown it can't hold. Without a count anything could.
+func (h *heuristic) overtakable(view *engine.PlayerView, c card.Card) bool {
+	t := h.counts(view)
+	switch {
+	case c.IsWizard:
+		return false
+	case t == nil || c.IsJoker:
+		return true
+	}
+	trumped := view.TrumpSuit != "" && c.Symbol != view.TrumpSuit && t.TrumpsOut() > 0
+	if t.WizardsOut() == 0 && !trumped && t.HighestOut(c.Symbol) <= c.Number {
+		return false
+	}
+	return len(overtakers(view, c, t.Possible)) > 0
+}
+
+// overtakers returns the seats still to play holding a card that would take
+// the trick from c, their cards as held returns them.
+func overtakers(view *engine.PlayerView, c card.Card, held func(seat int) []card.Card) []int {
+	trick := view.Trick.Clone()
+	trick.Play(view.Seat, c)
+	var seats []int
+	for _, seat := range view.StillToPlay() {
+		if slices.ContainsFunc(held(seat), trick.Beats) {
+			seats = append(seats, seat)
+		}
+	}
+	return seats
+}
+
+// safeWinner plays the lowest winner likely enough to hold against the
+// seats still to play; the more risk the seat takes, the lower the bar.
+func (h *heuristic) safeWinner(view *engine.PlayerView, winners []card.Card) card.Card {
+	k := h.knowledge(view)
+	winners = sortByRank(winners, view.TrumpSuit)
+	for _, c := range winners {
+		if h.holdProbability(view, c, k) >= 1-h.RiskAppetite {
+			return c
+		}
+	}
+	return winners[len(winners)-1]
+}
+
+// holdProbability estimates the chance c still takes the trick once every
+// seat still to play has played.
+func (h *heuristic) holdProbability(view *engine.PlayerView, c card.Card, k knowledge) float64 {
+	later := view.StillToPlay()
+	if len(later) == 0 {
+		return 1
+	}
+
+	// Seats that made their prediction duck rather than overtake, unless
+	// they are known to play risky. Their Wizards weigh as much as they are
+	// known to be spent at this point of the turn.
+	threat, wizardThreat := float64(len(later)), float64(len(later))
+	if h.modeling {
+		threat, wizardThreat = 0, 0
+		early := len(view.History) < view.HandSize/2
+		for _, seat := range later {
+			var seatThreat float64
+			if view.Need(seat) > 0 {
+				seatThreat = 1
+			} else if profile := h.opponent(seat); profile != nil {
+				seatThreat = profile.Risk()
+			} else {
+				seatThreat = h.tuning().DuckingThreat
+			}
+			threat += seatThreat
+			wizardThreat += seatThreat * wizardLean(h.opponent(seat), early)
+		}
+	}
+
+	opponents := float64(view.NumPlayers() - 1)
+	wizards := float64(k.wizards())
+	exposure := (k.beaters(c, view.TrumpSuit)-wizards)*threat/opponents + wizards*wizardThreat/opponents
+	return math.Pow(1-k.beatChance(), exposure)
+}
+
+// wizardLean scales the chance a player spends a Wizard now by how early in
+// a turn it is known to spend them: 1 for an unknown player, up to 2 for one
+// spending them all in the half of the turn under way.
+func wizardLean(profile *OpponentProfile, early bool) float64 {
+	dumping := profile.WizardDumping()
+	if !early {
+		dumping = 1 - dumping
+	}
+	return dumping / 0.5
+}
+
+// blocking reports whether an opponent still to play to the trick needs tricks.
+func blocking(view *engine.PlayerView) bool {
+	for _, seat := range view.StillToPlay() {
+		if view.Bids[seat] >= 0 && view.Need(seat) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// split separates the cards that would take the trick from those that wouldn't.
+func split(cards []card.Card, trick *engine.Trick) (winners, losers []card.Card) {
+	for _, c := range cards {
+		if trick.Beats(c) {
+			winners = append(winners, c)
+		} else {
+			losers = append(losers, c)
+		}
+	}
+	return winners, losers
+}
+
+// rank orders cards by how likely they are to win a trick of their own.
+func rank(c card.Card, trump card.Symbol) int {
+	switch {
+	case c.IsJoker:
+		return 0
+	case c.IsWizard:
+		return 30
+	case trump != "" && c.Symbol == trump:
+		return c.Number + 13
+	}
+	return c.Number
+}
+
+func sortByRank(cards []card.Card, trump card.Symbol) []card.Card {
+	sorted := slices.Clone(cards)
+	slices.SortStableFunc(sorted, func(a, b card.Card) int { return rank(a, trump) - rank(b, trump) })
+	return sorted
+}
+
+// lowest returns the lowest ranked of cards, or of fallback when cards is empty.
+func lowest(cards []card.Card, trump card.Symbol, fallback []card.Card) card.Card {
+	if len(cards) == 0 {
+		cards = fallback
+	}
+	pick := cards[0]
+	for _, c := range cards[1:] {
+		if rank(c, trump) < rank(pick, trump) {
+			pick = c
+		}
+	}
+	return pick
+}
+
+// highest returns the highest ranked of cards, or of fallback when cards is empty.
+func highest(cards []card.Card, trump card.Symbol, fallback []card.Card) card.Card {
+	if len(cards) == 0 {
+		cards = fallback
+	}
+	pick := cards[0]
+	for _, c := range cards[1:] {
+		if rank(c, trump) > rank(pick, trump) {
+			pick = c
+		}
+	}
+	return pick
+}
+
+func withoutWizards(cards []card.Card) []card.Card {
+	var out []card.Card
+	for _, c := range cards {
+		if !c.IsWizard {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func isWizard(c card.Card) bool { return c.IsWizard }
+
+func isJoker(c card.Card) bool { return c.IsJoker }
+
+func find(cards []card.Card, match func(card.Card) bool) (card.Card, bool) {
+	for _, c := range cards {
+		if match(c) {
+			return c, true
+		}
+	}
+	return card.Card{}, false
+}
@@ -0,0 +1,352 @@
+package ai
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// Deals played out for every decision
+const (
+	// Hard: a shallow look-ahead against a model of the opponents
+	hardSamples = 4
+	// Expert: Monte Carlo simulations
+	expertSamples = 20
+	// The fewest for a bid: every bid is tried, and over fewer deals the
+	// best looking of so many is mostly luck
+	bidSamples = 10
+)
+
+// dealAttempts bounds the tries at dealing the unseen cards around the
+// opponents' known voids before the voids are ignored.
+const dealAttempts = 20
+
+// Search bounds the work of a simulating agent on every decision.
+type Search struct {
+	Iterations int           // deals sampled
+	Budget     time.Duration // wall-clock limit, 0 for none
+}
+
+// simulation is perfect information Monte Carlo: it deals the unseen cards to
+// the opponents many times over, consistently with what they have shown they
+// lack, plays each candidate out to the end of the turn, with every seat
+// played by a heuristic player bidding and playing for its own hand, and
+// keeps the candidate with the best expected score. A modeling agent has
+// those players read the predictions and the known habits of the table.
+//
+// With no Budget the decisions depend on the seed only.
+type simulation struct {
+	heuristic
+	search Search
+	rng    *rand.Rand
+	// the policy every seat follows during the playouts
+	rollout heuristic
+	// when to solve the rest of the turn instead of sampling it
+	endgame Endgame
+	// how the game stands, nil when the agent plays for the points of the turn
+	standings *Standings
+}
+
+// ExpertSearch bounds the expert level's search.
+var ExpertSearch = Search{Iterations: expertSamples}
+
+// NewPIMC creates a simulating agent with its own search bounds.
+func NewPIMC(personality Personality, search Search, seed int64) Agent {
+	return newSimulation(personality, search, seed)
+}
+
+func newSimulation(personality Personality, search Search, seed int64) *simulation {
+	return &simulation{
+		heuristic: heuristic{Personality: personality, counting: true},
+		search:    search,
+		rng:       rand.New(rand.NewSource(seed)),
+		rollout:   heuristic{Personality: Balanced},
+	}
+}
+
+// modeled has the agent and the seats it plays out read the opponents'
+// predictions and habits.
+func (s *simulation) modeled() *simulation {
+	s.modeling = true
+	s.rollout.modeling = true
+	return s
+}
+
+// Read takes the profiles of the players at the table, for the playouts of
+// a modeling agent as well.
+func (s *simulation) Read(opponents []*OpponentProfile) {
+	s.heuristic.Read(opponents)
+	if s.rollout.modeling {
+		s.rollout.Read(opponents)
+	}
+}
+
+// tuned has the agent and the seats it models play on weights.
+func (s *simulation) tuned(weights *Weights) *simulation {
+	s.weights = weights
+	s.rollout.weights = weights
+	return s
+}
+
+// solving has the agent solve the last tricks of a turn exactly.
+func (s *simulation) solving(endgame Endgame) *simulation {
+	s.endgame = endgame
+	return s
+}
+
+// Bid tries every bid from 0 to the hand size and keeps the best, or looks
+// the hand up in the bid tables when they are loaded and the turn is played
+// for points.
+func (s *simulation) Bid(view *engine.PlayerView) int {
+	if dist, ok := BidTables.Distribution(view); ok && !s.standings.final() {
+		bid, _ := bestExpected(dist)
+		if s.explaining {
+			s.rationale = &Rationale{
+				Decision:   fmt.Sprintf("bid %d", bid),
+				Rule:       "best expected score over the tricks the bid tables give the hand",
+				Cards:      s.contributions(view, s.knowledge(view), 1),
+				Candidates: bidCandidates(dist),
+			}
+		}
+		return bid
+	}
+
+	// Every bid is played out on the same deals
+	moves := make([]engine.Action, len(view.Hand)+1)
+	for bid := range moves {
+		moves[bid] = engine.BidOf(bid)
+	}
+	search := s.search
+	search.Iterations = max(search.Iterations, bidSamples)
+	totals, deals := s.evaluate(view, moves, search)
+
+	pick := best(totals)
+	if s.explaining {
+		s.explainSamples(view, moves, totals, deals, fmt.Sprintf("%s over %d deals", s.objective(), deals))
+	}
+	return pick
+}
+
+// Play tries every legal card, or solves the endgame. In the final turns of a
+// game the endgame is sampled like the rest: the solver knows the seat's
+// points only, not the chance to win.
+func (s *simulation) Play(view *engine.PlayerView) card.Card {
+	legal := view.Trick.LegalPlays(view.Hand)
+	if len(legal) == 1 {
+		if s.explaining {
+			s.rationale = &Rationale{Decision: "play " + legal[0].String(), Rule: "the only legal card"}
+		}
+		return legal[0]
+	}
+	endgame := s.endgame
+	if s.standings.final() {
+		endgame = Endgame{}
+	}
+	if cards, scores, deals := SolveEndgame(view, endgame); deals > 0 {
+		pick := 0
+		for i, score := range scores {
+			if score > scores[pick] {
+				pick = i
+			}
+		}
+		if s.explaining {
+			s.explainEndgame(view, cards, scores, deals, pick)
+		}
+		return cards[pick]
+	}
+
+	moves := make([]engine.Action, len(legal))
+	for i, c := range legal {
+		moves[i] = engine.PlayOf(c)
+	}
+	totals, deals := s.evaluate(view, moves, s.search)
+
+	pick := legal[best(totals)]
+	if s.explaining {
+		s.explainSamples(view, moves, totals, deals, fmt.Sprintf("%s over %d deals", s.objective(), deals))
+	}
+	return pick
+}
+
+// evaluate plays every move out on the same sampled deals and returns the
+// total value of each, with the number of deals. The value of a playout is
+// the seat's score, or in the final turns of a game its chance to win.
+func (s *simulation) evaluate(view *engine.PlayerView, moves []engine.Action, search Search) ([]float64, int) {
+	totals := make([]float64, len(moves))
+	deals := 0
+	tracker := TrackerFromView(view)
+	for sample := samples(search); sample(); deals++ {
+		deal := deal(view, tracker, s.rng)
+		for i, move := range moves {
+			state := deal.Clone()
+			state.Apply(move)
+			totals[i] += s.value(state, view.Seat)
+		}
+	}
+	return totals, deals
+}
+
+// tieBreak weighs a point against the chance to win, so little that no
+// score of a turn outweighs a percent of chance.
+const tieBreak = 1e-5
+
+// value plays the state out and values it for seat.
+func (s *simulation) value(state *engine.State, seat int) float64 {
+	score := s.playout(state, seat)
+	if !s.standings.final() {
+		return float64(score)
+	}
+	scores := slices.Clone(s.standings.Scores)
+	for other, points := range state.Scores() {
+		scores[other] += points
+	}
+	// The points break the ties, when every move wins or loses all the same
+	return WinChance(scores, seat, s.standings.TurnsLeft) + float64(score)*tieBreak
+}
+
+// objective tells what the agent's moves are weighed by.
+func (s *simulation) objective() string {
+	if s.standings.final() {
+		return "best chance to win the game"
+	}
+	return "best average score"
+}
+
+// Evaluate scores every decision open to the view's seat by its average
+// score over deals consistent with the view, each played out to the end of
+// the turn by the heuristic players the simulating levels model. It is the
+// expert's judgement of a position, for reviewing the decisions of a game.
+func Evaluate(view *engine.PlayerView, search Search, seed int64) ([]engine.Action, []float64) {
+	hands := make([][]card.Card, view.NumPlayers())
+	hands[view.Seat] = view.Hand
+	moves := engine.StateFromView(view, hands).LegalActions()
+
+	totals, deals := newSimulation(Balanced, search, seed).evaluate(view, moves, search)
+	for i := range totals {
+		totals[i] /= float64(deals)
+	}
+	return moves, totals
+}
+
+// explainSamples keeps the average score of every move over the deals.
+func (s *simulation) explainSamples(view *engine.PlayerView, moves []engine.Action, totals []float64, deals int, rule string) {
+	candidates := make([]Candidate, len(moves))
+	for i, move := range moves {
+		candidates[i] = Candidate{Move: Describe(move), Score: totals[i] / float64(deals)}
+	}
+	s.rationale = &Rationale{
+		Decision:   Describe(moves[best(totals)]),
+		Rule:       rule,
+		Cards:      s.contributions(view, s.knowledge(view), 1),
+		Candidates: candidates,
+	}
+}
+
+// samples returns a function telling whether to sample one more deal: the
+// first always, the rest while the search has iterations and time left.
+func samples(search Search) func() bool {
+	deadline := time.Now().Add(search.Budget)
+	done := 0
+	return func() bool {
+		if done > 0 && (done >= search.Iterations || search.Budget > 0 && time.Now().After(deadline)) {
+			return false
+		}
+		done++
+		return true
+	}
+}
+
+// playout finishes the turn with the rollout policy and returns seat's score.
+func (s *simulation) playout(state *engine.State, seat int) int {
+	for state.Phase != engine.Done {
+		view := state.View(state.ToAct)
+		if state.Phase == engine.Bidding {
+			state.Apply(engine.BidOf(s.rollout.Bid(&view)))
+		} else {
+			state.Apply(engine.PlayOf(s.rollout.Play(&view)))
+		}
+	}
+	return engine.Score(state.Bids[seat], state.Won[seat])
+}
+
+func best(totals []float64) int {
+	pick := 0
+	for i, total := range totals {
+		if total > totals[pick] {
+			pick = i
+		}
+	}
+	return pick
+}
+
+// deal guesses the opponents' hands: the unseen cards are shuffled and dealt,
+// as many to each opponent as it still holds. Each opponent first gets the
+// cards it surely holds and then only cards of the symbols it may have; the
+// most constrained opponents are dealt first.
+func deal(view *engine.PlayerView, tracker *Tracker, rng *rand.Rand) *engine.State {
+	possible, certain := tracker.holdings()
+
+	opponents := []int{}
+	for seat := range view.NumPlayers() {
+		if seat != view.Seat {
+			opponents = append(opponents, seat)
+		}
+	}
+	slices.SortStableFunc(opponents, func(a, b int) int {
+		return (len(possible[a]) - view.CardsLeft(a)) - (len(possible[b]) - view.CardsLeft(b))
+	})
+
+	pool := view.Unseen()
+	for _, seat := range opponents {
+		for _, c := range certain[seat] {
+			if i := slices.Index(pool, c); i >= 0 {
+				pool = slices.Delete(pool, i, i+1)
+			}
+		}
+	}
+
+	for range dealAttempts {
+		if hands, ok := dealAround(view, tracker, opponents, certain, slices.Clone(pool), rng); ok {
+			return engine.StateFromView(view, hands)
+		}
+	}
+
+	// The voids couldn't be met: deal blindly
+	unseen := view.Unseen()
+	rng.Shuffle(len(unseen), func(i, j int) { unseen[i], unseen[j] = unseen[j], unseen[i] })
+	hands := make([][]card.Card, view.NumPlayers())
+	hands[view.Seat] = view.Hand
+	for _, seat := range opponents {
+		left := view.CardsLeft(seat)
+		hands[seat], unseen = unseen[:left:left], unseen[left:]
+	}
+	return engine.StateFromView(view, hands)
+}
+
+// dealAround shuffles the pool and fills every opponent's hand with the
+// first cards it may hold.
+func dealAround(view *engine.PlayerView, tracker *Tracker, opponents []int, certain [][]card.Card, pool []card.Card, rng *rand.Rand) ([][]card.Card, bool) {
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	hands := make([][]card.Card, view.NumPlayers())
+	hands[view.Seat] = view.Hand
+	for _, seat := range opponents {
+		hand := slices.Clone(certain[seat])
+		for i := 0; i < len(pool) && len(hand) < view.CardsLeft(seat); {
+			if tracker.excluded(seat, pool[i]) {
+				i++
+				continue
+			}
+			hand = append(hand, pool[i])
+			pool = slices.Delete(pool, i, i+1)
+		}
+		if len(hand) < view.CardsLeft(seat) {
+			return nil, false
+		}
+		hands[seat] = hand
+	}
+	return hands, true
+}
@@ -0,0 +1,69 @@
+package ai_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/card"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// TestPIMCDeterministic checks that a seeded expert takes the same decisions
+func TestPIMCDeterministic(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	turnDeck := deck.InitDeck()
+	rng.Shuffle(len(turnDeck), func(i, j int) { turnDeck[i], turnDeck[j] = turnDeck[j], turnDeck[i] })
+	state := engine.NewState(4, 0, 6, turnDeck)
+
+	search := ai.Search{Iterations: 10}
+	first := ai.NewPIMC(ai.Balanced, search, 42)
+	second := ai.NewPIMC(ai.Balanced, search, 42)
+	for state.Phase != engine.Done {
+		view := state.View(state.ToAct)
+		if state.Phase == engine.Choosing {
+			state.Apply(arena.Decide(first, state))
+			continue
+		}
+		if state.Phase == engine.Bidding {
+			bid := first.Bid(&view)
+			if again := second.Bid(&view); again != bid {
+				t.Fatalf("seat %d bid %d, then %d with the same seed", state.ToAct, bid, again)
+			}
+			state.Apply(engine.BidOf(bid))
+			continue
+		}
+		c := first.Play(&view)
+		if again := second.Play(&view); again != c {
+			t.Fatalf("seat %d played %v, then %v with the same seed", state.ToAct, c, again)
+		}
+		state.Apply(engine.PlayOf(c))
+	}
+}
+
+// TestPIMCBid checks that the bid is the best of every bid from 0 to the
+// hand size, played out on the same deals
+func TestPIMCBid(t *testing.T) {
+	wizard := card.Card{IsWizard: true, Number: -1}
+	hand := []card.Card{wizard, wizard, wizard, {Number: 13, Symbol: card.Red}, {Number: 2, Symbol: card.Green}}
+	view := engine.PlayerView{Seat: 1, Dealer: 0, HandSize: len(hand), Hand: hand, TrumpSuit: card.Red,
+		Bids: []int{-1, -1, -1, -1}, Won: make([]int, 4), Trick: engine.NewTrick(card.Red)}
+
+	agent := ai.NewPIMC(ai.Balanced, ai.Search{Iterations: 20}, 3)
+	agent.(ai.Explainer).Explain()
+	bid := agent.Bid(&view)
+	candidates := agent.(ai.Explainer).Rationale().Candidates
+	if len(candidates) != len(hand)+1 {
+		t.Fatalf("%d bids tried on a hand of %d", len(candidates), len(hand))
+	}
+	for tried, candidate := range candidates {
+		if candidate.Move != fmt.Sprintf("bid %d", tried) || candidate.Score > candidates[bid].Score {
+			t.Errorf("bid %d, when %s scores %.1f", bid, candidate.Move, candidate.Score)
+		}
+	}
+	if bid < 3 {
+		t.Errorf("bid %d holding three Wizards", bid)
+	}
+}
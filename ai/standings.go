@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"math"
+	"slices"
+)
+
+// finalTurns is how many turns before the end of the game, this one
+// included, an agent plays for the game rather than for the points of the
+// turn. Earlier the chance to win grows with the points all the same.
+const finalTurns = 3
+
+// turnSpread is the spread of the points a seat makes in a turn, which the
+// chance to win assumes for every turn still to come.
+const turnSpread = 30
+
+// Standings is how the game stands as a turn is dealt.
+type Standings struct {
+	Scores    []int // the score of every seat before the turn
+	TurnsLeft int   // the turns still to be played after this one
+}
+
+// Contender is implemented by agents that play for the win once told the
+// standings: in the final turns a seat far behind gambles and the leader
+// plays safe.
+type Contender interface {
+	// Standings is told before every turn is dealt
+	Standings(standings Standings)
+}
+
+// final reports whether the turn is one of the final turns of the game.
+func (s *Standings) final() bool {
+	return s != nil && s.TurnsLeft < finalTurns
+}
+
+// WinChance estimates the chance seat wins the game with the given scores
+// and turnsLeft turns still to be played. Once none is left, the winner is
+// the highest score, ties sharing the win. Before that the difference
+// between two seats changes by the sum of their points in the coming turns,
+// taken as independent and normal with a spread of turnSpread per turn, and
+// the chances to end ahead of each other seat are multiplied.
+func WinChance(scores []int, seat, turnsLeft int) float64 {
+	if turnsLeft <= 0 {
+		best := slices.Max(scores)
+		if scores[seat] < best {
+			return 0
+		}
+		tied := 0
+		for _, score := range scores {
+			if score == best {
+				tied++
+			}
+		}
+		return 1 / float64(tied)
+	}
+
+	spread := turnSpread * math.Sqrt(2*float64(turnsLeft))
+	chance := 1.0
+	for other, score := range scores {
+		if other != seat {
+			chance *= 0.5 * math.Erfc(-float64(scores[seat]-score)/(spread*math.Sqrt2))
+		}
+	}
+	return chance
+}
+
+// Standings has the simulating agent weigh its moves by the chance to win
+// the game in the final turns.
+func (s *simulation) Standings(standings Standings) {
+	s.standings = &standings
+}
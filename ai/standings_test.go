@@ -0,0 +1,78 @@
+package ai_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// TestStandings checks the chance to win and that an expert far behind in
+// the last turn catches up more often playing for the win than for points
+func TestStandings(t *testing.T) {
+	if chance := ai.WinChance([]int{50, 30, 50}, 0, 0); chance != 0.5 {
+		t.Errorf("tied for the lead at the end: %.2f", chance)
+	}
+	if ai.WinChance([]int{10, 30, 20}, 0, 0) != 0 || ai.WinChance([]int{10, 30, 20}, 1, 0) != 1 {
+		t.Error("the highest score at the end does not win")
+	}
+	behind, near, ahead := ai.WinChance([]int{0, 60}, 0, 2), ai.WinChance([]int{50, 60}, 0, 2), ai.WinChance([]int{80, 60}, 0, 2)
+	if !(0 < behind && behind < near && near < 0.5 && 0.5 < ahead && ahead < 1) {
+		t.Errorf("chances to win %.2f, %.2f, %.2f", behind, near, ahead)
+	}
+
+	const gap = 25
+	caughtUp := make([]int, 2)
+	for contending := range 2 {
+		rng := rand.New(rand.NewSource(7))
+		for i := range 120 {
+			expert := ai.New(ai.Expert, ai.Balanced, int64(i))
+			if contending == 1 {
+				expert.(ai.Contender).Standings(ai.Standings{Scores: []int{gap, 0, -100}, TurnsLeft: 0})
+			}
+			agents := []ai.Agent{ai.New(ai.Medium, ai.Balanced, 1), expert, ai.New(ai.Medium, ai.Balanced, 2)}
+			if scores := arena.Turn(agents, 0, 3, rng, nil).Scores(); scores[1] > scores[0]+gap {
+				caughtUp[contending]++
+			}
+		}
+	}
+	if caughtUp[1] <= caughtUp[0] {
+		t.Errorf("caught up %d times playing for the win, %d for points", caughtUp[1], caughtUp[0])
+	}
+}
+
+// TestStandingsEndgame checks that an expert playing for the win weighs the
+// last tricks of the final turn by the chance to win, where it would solve
+// them for points
+func TestStandingsEndgame(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	turnDeck := deck.InitDeck()
+	rng.Shuffle(len(turnDeck), func(i, j int) { turnDeck[i], turnDeck[j] = turnDeck[j], turnDeck[i] })
+	state := engine.NewState(3, 0, 20, turnDeck)
+	others := ai.New(ai.Medium, ai.Balanced, 1)
+	for state.Phase != engine.Playing || len(state.Hands[1]) > 4 || state.ToAct != 1 {
+		if err := state.Apply(arena.Decide(others, state)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	view := state.View(1)
+	if len(view.Trick.LegalPlays(view.Hand)) < 2 {
+		t.Skip("the expert has a single legal card")
+	}
+
+	for _, contending := range []bool{false, true} {
+		expert := ai.New(ai.Expert, ai.Balanced, 1)
+		if contending {
+			expert.(ai.Contender).Standings(ai.Standings{Scores: []int{40, 0, 20}, TurnsLeft: 0})
+		}
+		expert.(ai.Explainer).Explain()
+		expert.Play(&view)
+		solved := strings.Contains(expert.(ai.Explainer).Rationale().Rule, "solved exactly")
+		if solved == contending {
+			t.Errorf("playing for the win %v, the expert decided by %q", contending, expert.(ai.Explainer).Rationale().Rule)
+		}
+	}
+}
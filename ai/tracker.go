@@ -0,0 +1,333 @@
+package ai
+
+import (
+	"slices"
+	"wizard/card"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// Tracker remembers, for one seat, which cards have gone this turn. It is fed
+// the engine events from that seat's side of the table, so it knows no more
+// than the seat legitimately does: its own hand, the public plays and the
+// revealed trump card.
+type Tracker struct {
+	seat       int
+	numPlayers int
+	handSize   int
+	hand       []card.Card
+	trump      card.Card
+	trumpSuit  card.Symbol
+	played     []card.Card
+	playedBy   [][]card.Card
+	trick      engine.Trick
+	voids      []map[card.Symbol]bool
+	dealt      bool
+}
+
+func NewTracker(seat int) *Tracker {
+	return &Tracker{seat: seat}
+}
+
+func (t *Tracker) Observe(e engine.Event) {
+	switch e.Kind {
+	case engine.DealEvent:
+		if e.Seat != t.seat {
+			return
+		}
+		// A new turn starts
+		*t = Tracker{
+			seat:       t.seat,
+			numPlayers: e.Players,
+			handSize:   e.HandSize,
+			hand:       slices.Clone(e.Hand),
+			playedBy:   make([][]card.Card, e.Players),
+			voids:      make([]map[card.Symbol]bool, e.Players),
+			dealt:      true,
+		}
+		for seat := range t.voids {
+			t.voids[seat] = make(map[card.Symbol]bool)
+		}
+	case engine.TrumpEvent:
+		t.trump = e.Card
+		t.trumpSuit = e.Suit
+		t.trick = engine.NewTrick(t.trumpSuit)
+	case engine.PlayEvent:
+		if !t.dealt {
+			return
+		}
+		if symbol, void := t.trick.ProvesVoid(e.Card); void {
+			t.voids[e.Seat][symbol] = true
+		}
+		t.trick.Play(e.Seat, e.Card)
+		if len(t.trick.Cards) == t.numPlayers {
+			t.trick = engine.NewTrick(t.trumpSuit)
+		}
+
+		t.played = append(t.played, e.Card)
+		t.playedBy[e.Seat] = append(t.playedBy[e.Seat], e.Card)
+		if e.Seat == t.seat {
+			if i := slices.Index(t.hand, e.Card); i >= 0 {
+				t.hand = slices.Delete(t.hand, i, i+1)
+			}
+		}
+	}
+}
+
+// TrackerFromView replays a seat's view of the turn into a new tracker, for
+// callers that don't follow the events themselves.
+func TrackerFromView(view *engine.PlayerView) *Tracker {
+	tricks := append(slices.Clone(view.History), view.Trick)
+
+	dealt := slices.Clone(view.Hand)
+	for _, trick := range tricks {
+		for i, seat := range trick.Seats {
+			if seat == view.Seat {
+				dealt = append(dealt, trick.Cards[i])
+			}
+		}
+	}
+
+	t := NewTracker(view.Seat)
+	t.Observe(engine.Event{Kind: engine.DealEvent, Seat: view.Seat, Players: view.NumPlayers(),
+		Dealer: view.Dealer, HandSize: view.HandSize, Hand: dealt})
+	t.Observe(engine.Event{Kind: engine.TrumpEvent, Seat: view.Dealer, Card: view.Trump, Suit: view.TrumpSuit})
+	for _, trick := range tricks {
+		for i, seat := range trick.Seats {
+			t.Observe(engine.Event{Kind: engine.PlayEvent, Seat: seat, Card: trick.Cards[i]})
+		}
+	}
+	return t
+}
+
+// Ready reports whether the tracker has seen the seat's deal this turn.
+func (t *Tracker) Ready() bool {
+	return t.dealt
+}
+
+func (t *Tracker) Seat() int {
+	return t.seat
+}
+
+// Hand is what the seat still holds.
+func (t *Tracker) Hand() []card.Card {
+	return t.hand
+}
+
+// Played returns the cards played this turn, in order.
+func (t *Tracker) Played() []card.Card {
+	return t.played
+}
+
+// PlayedBy returns the cards seat played this turn.
+func (t *Tracker) PlayedBy(seat int) []card.Card {
+	return t.playedBy[seat]
+}
+
+// Unseen returns the cards the seat hasn't seen: they are in the opponents'
+// hands or still in the deck.
+func (t *Tracker) Unseen() []card.Card {
+	unseen := deck.InitDeck()
+	remove := func(c card.Card) {
+		if i := slices.Index(unseen, c); i >= 0 {
+			unseen = slices.Delete(unseen, i, i+1)
+		}
+	}
+	for _, c := range t.hand {
+		remove(c)
+	}
+	for _, c := range t.played {
+		remove(c)
+	}
+	if t.trump != (card.Card{}) {
+		remove(t.trump)
+	}
+	return unseen
+}
+
+// OpponentCards counts the cards still held by the other seats.
+func (t *Tracker) OpponentCards() int {
+	count := 0
+	for seat, played := range t.playedBy {
+		if seat != t.seat {
+			count += t.handSize - len(played)
+		}
+	}
+	return count
+}
+
+// TrumpsOut counts the trumps not seen yet, 0 when there is no trump.
+func (t *Tracker) TrumpsOut() int {
+	if t.trumpSuit == "" {
+		return 0
+	}
+	return countSymbol(t.Unseen(), t.trumpSuit)
+}
+
+func (t *Tracker) WizardsOut() int {
+	return countWizards(t.Unseen())
+}
+
+func (t *Tracker) JokersOut() int {
+	count := 0
+	for _, c := range t.Unseen() {
+		if c.IsJoker {
+			count++
+		}
+	}
+	return count
+}
+
+// HighestOut returns the highest number of symbol not seen yet, 0 when every
+// card of the symbol has been seen.
+func (t *Tracker) HighestOut(symbol card.Symbol) int {
+	highest := 0
+	for _, c := range t.Unseen() {
+		if !c.IsWizard && !c.IsJoker && c.Symbol == symbol && c.Number > highest {
+			highest = c.Number
+		}
+	}
+	return highest
+}
+
+// knowledge is what the hand evaluation counts on.
+func (t *Tracker) knowledge() knowledge {
+	return knowledge{
+		unseen:     t.Unseen(),
+		opponents:  t.OpponentCards(),
+		tricks:     len(t.hand),
+		numPlayers: t.numPlayers,
+		weights:    DefaultWeights,
+	}
+}
+
+// IsVoid reports whether seat has shown it holds no numbered card of symbol
+// this turn.
+func (t *Tracker) IsVoid(seat int, symbol card.Symbol) bool {
+	return t.voids[seat][symbol]
+}
+
+// Voids returns the symbols seat has shown it holds none of.
+func (t *Tracker) Voids(seat int) []card.Symbol {
+	var voids []card.Symbol
+	for _, symbol := range card.Symbols {
+		if t.voids[seat][symbol] {
+			voids = append(voids, symbol)
+		}
+	}
+	return voids
+}
+
+// CardsLeft counts the cards seat still holds.
+func (t *Tracker) CardsLeft(seat int) int {
+	return t.handSize - len(t.playedBy[seat])
+}
+
+// Possible returns the cards seat may be holding, Certain those it surely
+// holds. The seat's own hand is both.
+func (t *Tracker) Possible(seat int) []card.Card {
+	possible, _ := t.holdings()
+	return possible[seat]
+}
+
+func (t *Tracker) Certain(seat int) []card.Card {
+	_, certain := t.holdings()
+	return certain[seat]
+}
+
+// holdings places the unseen cards in the opponents' hands. A card may be
+// held by any opponent not void in its symbol. A card becomes certain when
+// an opponent has exactly as many possible cards as it holds, or, once the
+// whole deck is dealt, when a single opponent can hold it.
+func (t *Tracker) holdings() (possible, certain [][]card.Card) {
+	unseen := t.Unseen()
+	n := len(t.playedBy)
+
+	// owner[i] is the seat surely holding unseen[i], -1 while unknown
+	owner := make([]int, len(unseen))
+	for i := range owner {
+		owner[i] = -1
+	}
+	undealt := len(unseen) - t.OpponentCards()
+
+	candidates := func(seat int) []int {
+		var items []int
+		for i, c := range unseen {
+			if owner[i] == seat || (owner[i] == -1 && !t.excluded(seat, c)) {
+				items = append(items, i)
+			}
+		}
+		return items
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for seat := range n {
+			if seat == t.seat {
+				continue
+			}
+			items := candidates(seat)
+			if len(items) != t.CardsLeft(seat) {
+				continue
+			}
+			for _, i := range items {
+				if owner[i] == -1 {
+					owner[i] = seat
+					changed = true
+				}
+			}
+		}
+
+		if undealt > 0 {
+			continue
+		}
+		for i, c := range unseen {
+			if owner[i] != -1 {
+				continue
+			}
+			holder, holders := -1, 0
+			for seat := range n {
+				if seat != t.seat && !t.excluded(seat, c) && t.room(seat, owner) {
+					holder, holders = seat, holders+1
+				}
+			}
+			if holders == 1 {
+				owner[i] = holder
+				changed = true
+			}
+		}
+	}
+
+	possible = make([][]card.Card, n)
+	certain = make([][]card.Card, n)
+	for seat := range n {
+		if seat == t.seat {
+			possible[seat] = slices.Clone(t.hand)
+			certain[seat] = slices.Clone(t.hand)
+			continue
+		}
+		for _, i := range candidates(seat) {
+			possible[seat] = append(possible[seat], unseen[i])
+			if owner[i] == seat {
+				certain[seat] = append(certain[seat], unseen[i])
+			}
+		}
+	}
+	return possible, certain
+}
+
+// excluded reports whether seat has shown it can't hold c.
+func (t *Tracker) excluded(seat int, c card.Card) bool {
+	return !c.IsWizard && !c.IsJoker && t.voids[seat][c.Symbol]
+}
+
+// room reports whether seat holds more cards than are already placed with it.
+func (t *Tracker) room(seat int, owner []int) bool {
+	placed := 0
+	for _, o := range owner {
+		if o == seat {
+			placed++
+		}
+	}
+	return placed < t.CardsLeft(seat)
+}
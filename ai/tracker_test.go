@@ -0,0 +1,140 @@
+package ai_test
+
+import (
+	"fmt"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/card"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// TestVoidInference checks the trackers' inferences against the real hands
+func TestVoidInference(t *testing.T) {
+	for turn := 0; turn < 200; turn++ {
+		numPlayers := 3 + turn%4
+		handSize := 1 + turn%(60/numPlayers)
+		turnDeck := deck.InitDeck()
+		turnDeck.Shuffle()
+		state := engine.NewState(numPlayers, turn%numPlayers, handSize, turnDeck)
+
+		trackers := make([]*ai.Tracker, numPlayers)
+		agents := make([]ai.Agent, numPlayers)
+		for seat := range trackers {
+			trackers[seat] = ai.NewTracker(seat)
+			state.Observe(seat, trackers[seat].Observe)
+			agents[seat] = ai.New(ai.Medium, ai.Balanced, int64(turn*numPlayers+seat))
+		}
+
+		for state.Phase != engine.Done {
+			if err := state.Apply(arena.Decide(agents[state.ToAct], state)); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, tracker := range trackers {
+				for seat, hand := range state.Hands {
+					for _, symbol := range tracker.Voids(seat) {
+						for _, c := range hand {
+							if !c.IsWizard && !c.IsJoker && c.Symbol == symbol {
+								t.Fatalf("seat %d holds %v but was inferred void in %s", seat, c, symbol)
+							}
+						}
+					}
+					if !covers(tracker.Possible(seat), hand) {
+						t.Fatalf("seat %d holds %v, outside its possible cards %v", seat, hand, tracker.Possible(seat))
+					}
+					if !covers(hand, tracker.Certain(seat)) {
+						t.Fatalf("seat %d holds %v, not all of its certain cards %v", seat, hand, tracker.Certain(seat))
+					}
+				}
+			}
+		}
+	}
+}
+
+// covers reports whether every card of part is in whole, counting duplicates
+func covers(whole, part []card.Card) bool {
+	left := append([]card.Card(nil), whole...)
+	for _, c := range part {
+		found := false
+		for i := range left {
+			if left[i] == c {
+				left = append(left[:i], left[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// TestTrackerCounts follows a turn from the first seat and checks what its
+// count has still out after the trump is turned up and after every trick
+func TestTrackerCounts(t *testing.T) {
+	wizard := card.Card{IsWizard: true, Number: -1}
+	joker := card.Card{IsJoker: true, Number: -1}
+	red := func(n int) card.Card { return card.Card{Number: n, Symbol: card.Red} }
+	green := func(n int) card.Card { return card.Card{Number: n, Symbol: card.Green} }
+	blue := func(n int) card.Card { return card.Card{Number: n, Symbol: card.Blue} }
+
+	// The deck is drawn from its end: the first seat's hand comes last
+	hands := [][]card.Card{{red(13), green(10), wizard}, {red(2), green(3), joker}, {green(12), blue(1), blue(4)}}
+	turnDeck := deck.Deck{red(5)}
+	for seat := len(hands) - 1; seat >= 0; seat-- {
+		turnDeck = append(turnDeck, hands[seat]...)
+	}
+	state := engine.NewState(3, 2, 3, turnDeck)
+	tracker := ai.NewTracker(0)
+	state.Observe(0, tracker.Observe)
+
+	type counts struct{ trumps, wizards, jokers, highestRed, highestGreen int }
+	check := func(when string, want counts) {
+		t.Helper()
+		got := counts{tracker.TrumpsOut(), tracker.WizardsOut(), tracker.JokersOut(), tracker.HighestOut(card.Red), tracker.HighestOut(card.Green)}
+		if got != want {
+			t.Errorf("%s: %+v out, want %+v", when, got, want)
+		}
+	}
+	// The turned-up Red 5 and the hand's Red 13 are seen
+	check("trump turned up", counts{trumps: 11, wizards: 3, jokers: 4, highestRed: 12, highestGreen: 13})
+
+	for _, bid := range []int{1, 1, 1} {
+		state.Apply(engine.BidOf(bid))
+	}
+	tricks := []struct {
+		plays []card.Card
+		want  counts
+	}{
+		{[]card.Card{green(10), green(3), green(12)}, counts{trumps: 11, wizards: 3, jokers: 4, highestRed: 12, highestGreen: 13}},
+		{[]card.Card{blue(1), wizard, joker}, counts{trumps: 11, wizards: 3, jokers: 3, highestRed: 12, highestGreen: 13}},
+		{[]card.Card{red(13), red(2), blue(4)}, counts{trumps: 10, wizards: 3, jokers: 3, highestRed: 12, highestGreen: 13}},
+	}
+	for i, trick := range tricks {
+		for _, c := range trick.plays {
+			if err := state.Apply(engine.PlayOf(c)); err != nil {
+				t.Fatalf("trick %d: %v", i+1, err)
+			}
+		}
+		check(fmt.Sprintf("after trick %d", i+1), trick.want)
+	}
+	if !tracker.IsVoid(2, card.Red) || tracker.IsVoid(0, card.Blue) {
+		t.Errorf("voids %v and %v", tracker.Voids(2), tracker.Voids(0))
+	}
+
+	// A Wizard turned up is seen, and the trump named counts from then on
+	turnDeck = deck.Deck{wizard, green(13), red(1), blue(7)}
+	state = engine.NewState(3, 0, 1, turnDeck)
+	tracker = ai.NewTracker(1)
+	state.Observe(1, tracker.Observe)
+	if tracker.WizardsOut() != 3 || tracker.TrumpsOut() != 0 {
+		t.Errorf("%d Wizards and %d trumps out before the trump is named", tracker.WizardsOut(), tracker.TrumpsOut())
+	}
+	state.Apply(engine.TrumpOf(card.Green))
+	if tracker.TrumpsOut() != 13 || tracker.HighestOut(card.Green) != 13 {
+		t.Errorf("%d Green trumps up to %d out", tracker.TrumpsOut(), tracker.HighestOut(card.Green))
+	}
+}
@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"slices"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// TrumpOption is how a hand fares with one symbol as trump.
+type TrumpOption struct {
+	Symbol card.Symbol
+	Tricks float64 // tricks expected
+	Bid    int     // the bid with the best expected score
+	Score  float64 // its expected score
+}
+
+// EvaluateTrumps rates every symbol as trump for the dealer's hand after a
+// Wizard was turned up, best first. The length and the height of a symbol
+// and the Wizards held all go into the tricks every card is expected to take.
+//
+// A symbol is rated by the best expected score of a bid on the hand rather
+// than by the tricks it promises: a symbol that makes the hand easy to
+// predict is worth more than one promising a trick more with no certainty.
+// The hand is rated alone; where the dealer bids and plays in the turn is
+// left out.
+func EvaluateTrumps(hand []card.Card, numPlayers int) []TrumpOption {
+	k := handKnowledge(hand, numPlayers)
+	// The Wizard turned up is out of play
+	if i := slices.IndexFunc(k.unseen, isWizard); i >= 0 {
+		k.unseen = slices.Delete(slices.Clone(k.unseen), i, i+1)
+	}
+
+	options := make([]TrumpOption, 0, len(card.Symbols))
+	for _, symbol := range card.Symbols {
+		option := TrumpOption{Symbol: symbol}
+		probabilities := make([]float64, len(hand))
+		for i, c := range hand {
+			probabilities[i] = cardWinProbability(c, symbol, k)
+			option.Tricks += probabilities[i]
+		}
+		option.Bid, option.Score = bestExpected(tricksDistribution(probabilities))
+		options = append(options, option)
+	}
+
+	slices.SortStableFunc(options, func(a, b TrumpOption) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	return options
+}
+
+// Trump names the symbol rated best for the hand.
+func (h *heuristic) Trump(view *engine.PlayerView) card.Symbol {
+	options := EvaluateTrumps(view.Hand, view.NumPlayers())
+	if h.explaining {
+		h.explainTrump(view, options)
+	}
+	return options[0].Symbol
+}
@@ -0,0 +1,20 @@
+package ai_test
+
+import (
+	"testing"
+	"wizard/ai"
+	"wizard/card"
+)
+
+// TestWizardTrump checks that the evaluation favours a long, high symbol
+func TestWizardTrump(t *testing.T) {
+	hand := []card.Card{
+		{Number: 13, Symbol: card.Green},
+		{Number: 11, Symbol: card.Green},
+		{Number: 8, Symbol: card.Green},
+		{Number: 2, Symbol: card.Red},
+	}
+	if best := ai.EvaluateTrumps(hand, 4)[0]; best.Symbol != card.Green {
+		t.Errorf("%s rated the best trump for three high Elves", best.Symbol)
+	}
+}
@@ -0,0 +1,177 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// tutorConfidence is the chance the range of a bid advice holds the tricks taken.
+const tutorConfidence = 0.8
+
+// Tutor advises a human seat on its decisions, the way the strongest
+// heuristic player would take them.
+type Tutor struct {
+	advisor heuristic
+}
+
+func NewTutor() *Tutor {
+	return &Tutor{advisor: heuristic{Personality: Balanced, counting: true, modeling: true, explaining: true}}
+}
+
+// Read has the tutor weigh the players after the seat by what is known of
+// them: how often they risk a trick and when they spend their Wizards.
+func (t *Tutor) Read(opponents []*OpponentProfile) {
+	t.advisor.Read(opponents)
+}
+
+// BidAdvice is a suggested bid and how sure the tutor is of it.
+type BidAdvice struct {
+	Bid      int
+	Expected float64 // tricks expected
+	// the tricks taken fall between Low and High with tutorConfidence
+	Low, High int
+}
+
+// Bid suggests the bid with the best expected score over the distribution of
+// the tricks the hand takes, simulated from the seat's place at the table.
+func (t *Tutor) Bid(view *engine.PlayerView) BidAdvice {
+	position := Position(view)
+	earlier := make([]int, position)
+	for i := range earlier {
+		earlier[i] = view.Bids[(view.Dealer+1+i)%view.NumPlayers()]
+	}
+	dist, err := TrickDistribution(view.Hand, view.Trump, view.TrumpSuit, view.NumPlayers(), position, earlier)
+	if err != nil {
+		// The hand alone still gives an estimate
+		probabilities := make([]float64, len(view.Hand))
+		k := t.advisor.knowledge(view)
+		for i, c := range view.Hand {
+			probabilities[i] = cardWinProbability(c, view.TrumpSuit, k)
+		}
+		dist = tricksDistribution(probabilities)
+	}
+
+	advice := BidAdvice{High: len(dist) - 1}
+	advice.Bid, _ = bestExpected(dist)
+	below := 0.0
+	tail := (1 - tutorConfidence) / 2
+	for tricks, q := range dist {
+		advice.Expected += float64(tricks) * q
+		if below+q <= tail {
+			advice.Low = tricks + 1
+		}
+		below += q
+	}
+	above := 0.0
+	for tricks := len(dist) - 1; tricks > advice.Low && above+dist[tricks] <= tail; tricks-- {
+		above += dist[tricks]
+		advice.High = tricks - 1
+	}
+	return advice
+}
+
+// PlayAdvice is a suggested card and the cards that would take the trick.
+type PlayAdvice struct {
+	Card card.Card
+	Why  string
+	// the legal cards that would take the trick as it stands
+	Winning []card.Card
+	// Out counts the Wizards, Jokers and trumps not seen yet
+	Out string
+}
+
+func (t *Tutor) Play(view *engine.PlayerView) PlayAdvice {
+	advice := PlayAdvice{Card: t.advisor.Play(view), Why: t.advisor.Rationale().Rule}
+	if len(view.Trick.Cards) > 0 {
+		advice.Winning, _ = split(view.Trick.LegalPlays(view.Hand), &view.Trick)
+	}
+	advice.Out = outstanding(t.advisor.counts(view), view.TrumpSuit)
+	return advice
+}
+
+// outstanding says what the count has still out, as "2 Wizards, 1 Joker and
+// 4 trumps up to 12 still out".
+func outstanding(t *Tracker, trump card.Symbol) string {
+	plural := func(n int, what string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", what)
+		}
+		return fmt.Sprintf("%d %ss", n, what)
+	}
+	parts := []string{plural(t.WizardsOut(), "Wizard"), plural(t.JokersOut(), "Joker")}
+	switch trumps := t.TrumpsOut(); {
+	case trump == "":
+	case trumps == 0:
+		parts = append(parts, "no trump")
+	default:
+		parts = append(parts, fmt.Sprintf("%s up to %d", plural(trumps, "trump"), t.HighestOut(trump)))
+	}
+	last := len(parts) - 1
+	return strings.Join(parts[:last], ", ") + " and " + parts[last] + " still out"
+}
+
+// Warn tells why playing c looks like a mistake, "" when it doesn't: taking
+// a trick with the bid already made when a card would lose it, letting a
+// trick go when every trick left is needed, wasting a Wizard on a trick
+// another Wizard already took, or playing for a trick with a card a player
+// after us surely beats when another card can't be beaten.
+func (t *Tutor) Warn(view *engine.PlayerView, c card.Card) string {
+	legal := view.Trick.LegalPlays(view.Hand)
+	need := view.Need(view.Seat)
+	k := t.advisor.knowledge(view)
+	tracker := t.advisor.counts(view)
+
+	if len(view.Trick.Cards) == 0 {
+		if need <= 0 && c.IsWizard && len(withoutWizards(legal)) > 0 {
+			return "your bid is made, and a Wizard led takes the trick"
+		}
+		return ""
+	}
+
+	winners, losers := split(legal, &view.Trick)
+	wins := view.Trick.Beats(c)
+	switch {
+	case c.IsWizard && !wins:
+		return "a Wizard already takes this trick: yours is wasted"
+	case need <= 0 && wins && len(losers) > 0 && !t.advisor.overtakable(view, c):
+		return fmt.Sprintf("your bid is made, nobody after you can beat %s%s and %s would lose it", c, shortOf(view, tracker), losers[0])
+	case need <= 0 && wins && len(losers) > 0 && t.advisor.holdProbability(view, c, k) > 0.9:
+		return fmt.Sprintf("your bid is made, %s would most likely take this trick and %s would lose it", c, losers[0])
+	case need >= view.TricksLeft() && !wins && len(winners) > 0:
+		return fmt.Sprintf("you need every trick left, %s loses this one and %s would take it", c, winners[0])
+	case need > 0 && wins && len(overtakers(view, c, tracker.Certain)) > 0:
+		for _, w := range winners {
+			if !t.advisor.overtakable(view, w) {
+				return fmt.Sprintf("a player after you surely holds a card beating %s, nobody can beat %s", c, w)
+			}
+		}
+	}
+	return ""
+}
+
+// shortOf tells, as ", no Elf being left after you", when every player after
+// the seat has shown it holds none of the suit to follow.
+func shortOf(view *engine.PlayerView, tracker *Tracker) string {
+	later := view.StillToPlay()
+	if view.Trick.Suit == "" || len(later) == 0 {
+		return ""
+	}
+	for _, seat := range later {
+		if !slices.Contains(tracker.Voids(seat), view.Trick.Suit) {
+			return ""
+		}
+	}
+	return fmt.Sprintf(", no %s being left after you", view.Trick.Suit)
+}
+
+// String writes the advice as the tutor says it.
+func (a BidAdvice) String() string {
+	if a.Low == a.High {
+		return fmt.Sprintf("bid %d: you should take %d tricks", a.Bid, a.Low)
+	}
+	return fmt.Sprintf("bid %d: you should take %d to %d tricks (%.0f%% sure), %.1f on average", a.Bid, a.Low, a.High, math.Round(100*tutorConfidence), a.Expected)
+}
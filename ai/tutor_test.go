@@ -0,0 +1,124 @@
+package ai_test
+
+import (
+	"strings"
+	"testing"
+	"wizard/ai"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// TestTutor checks the tutor's bid range and its warnings before a bad play
+func TestTutor(t *testing.T) {
+	tutor := ai.NewTutor()
+	wizard, low := card.Card{IsWizard: true, Number: -1}, card.Card{Number: 2, Symbol: card.Green}
+	view := engine.PlayerView{Seat: 1, Dealer: 0, HandSize: 2, Hand: []card.Card{wizard, low}, TrumpSuit: card.Red,
+		Bids: []int{-1, -1, -1}, Won: make([]int, 3), Trick: engine.NewTrick(card.Red)}
+
+	advice := tutor.Bid(&view)
+	if advice.Low > advice.Bid || advice.Bid > advice.High || advice.Bid < 1 || advice.Expected < 0.8 {
+		t.Errorf("advised %+v on a Wizard", advice)
+	}
+
+	view.Bids = []int{0, 0, 1}
+	view.Trick.Play(2, card.Card{Number: 5, Symbol: card.Green})
+	view.Trick.Play(0, card.Card{Number: 9, Symbol: card.Green})
+	if tutor.Warn(&view, wizard) == "" {
+		t.Error("no warning before taking a trick with the bid made")
+	}
+	if warning := tutor.Warn(&view, low); warning != "" {
+		t.Errorf("warned before a losing card with the bid made: %s", warning)
+	}
+	if play := tutor.Play(&view); play.Card != low || len(play.Winning) != 1 || play.Winning[0] != wizard {
+		t.Errorf("advised %+v with the bid made", play)
+	} else if play.Out != "3 Wizards, 4 Jokers and 13 trumps up to 13 still out" {
+		t.Errorf("counted %q", play.Out)
+	}
+
+	view.Bids[1] = 2
+	if tutor.Warn(&view, low) == "" {
+		t.Error("no warning before losing a trick the bid needs")
+	}
+}
+
+// TestTutorInference checks the tutor warns on what the players after the
+// seat have shown they hold: none of the suit, or surely a higher card
+func TestTutorInference(t *testing.T) {
+	tutor := ai.NewTutor()
+	wizard := card.Card{IsWizard: true, Number: -1}
+	joker := card.Card{IsJoker: true, Number: -1}
+	green := func(n int) card.Card { return card.Card{Number: n, Symbol: card.Green} }
+	blue := func(n int) card.Card { return card.Card{Number: n, Symbol: card.Blue} }
+	yellow := func(n int) card.Card { return card.Card{Number: n, Symbol: card.Yellow} }
+	red := func(n int) card.Card { return card.Card{Number: n, Symbol: card.Red} }
+
+	// history adds the tricks to the view, the first led by the dealer and
+	// every other by the winner of the one before
+	history := func(view *engine.PlayerView, tricks ...[]card.Card) {
+		leader := view.Dealer
+		for _, cards := range tricks {
+			trick := engine.NewTrick(view.TrumpSuit)
+			for i, c := range cards {
+				trick.Play((leader+i)%view.NumPlayers(), c)
+			}
+			view.History = append(view.History, trick)
+			leader = trick.Winner()
+			view.Won[leader]++
+		}
+	}
+
+	// The last seat has shown it holds no Green: the Green 9 surely takes
+	// a trick the bid doesn't need
+	view := engine.PlayerView{Seat: 1, Dealer: 0, HandSize: 5, Hand: []card.Card{green(9), green(3)},
+		Bids: []int{3, 0, 0}, Won: make([]int, 3), Trick: engine.NewTrick("")}
+	history(&view, []card.Card{wizard, wizard, wizard}, []card.Card{wizard, joker, joker}, []card.Card{green(2), green(1), blue(3)})
+	view.Trick.Play(0, green(5))
+	if warning := tutor.Warn(&view, green(9)); !strings.Contains(warning, "nobody after you can beat") || !strings.Contains(warning, "no Elf being left") {
+		t.Errorf("warned %q before a Green nobody can beat", warning)
+	}
+
+	// The whole deck is dealt and only the seat after can hold Green: it
+	// surely holds a Green above 10
+	hand := []card.Card{green(10), wizard}
+	for n := 2; n <= 11; n++ {
+		hand = append(hand, red(n))
+	}
+	view = engine.PlayerView{Seat: 1, Dealer: 2, HandSize: 15, Hand: hand,
+		Bids: []int{1, 2, 1, 1}, Won: make([]int, 4), Trick: engine.NewTrick("")}
+	history(&view, []card.Card{green(1), blue(2), blue(3), green(4)},
+		[]card.Card{yellow(1), yellow(2), wizard, yellow(3)},
+		[]card.Card{yellow(4), wizard, yellow(5), yellow(6)})
+	view.Trick.Play(0, joker)
+	if warning := tutor.Warn(&view, green(10)); !strings.Contains(warning, "surely holds a card beating") {
+		t.Errorf("warned %q before a Green the next seat surely beats", warning)
+	}
+}
+
+// TestTutorReadsWizards checks the tutor counts less on the Wizards of a
+// player after the seat known to keep them for the end of a turn
+func TestTutorReadsWizards(t *testing.T) {
+	green := func(n int) card.Card { return card.Card{Number: n, Symbol: card.Green} }
+	hand := []card.Card{green(12), green(2)}
+	for n := 2; n < 12; n++ {
+		hand = append(hand, card.Card{Number: n, Symbol: card.Blue})
+	}
+	view := engine.PlayerView{Seat: 1, Dealer: 0, HandSize: 12, Hand: hand, TrumpSuit: card.Red,
+		Bids: []int{0, 0, 1}, Won: make([]int, 3), Trick: engine.NewTrick(card.Red)}
+	view.Trick.Play(0, green(5))
+
+	for _, tc := range []struct {
+		name    string
+		profile *ai.OpponentProfile
+		warned  bool
+	}{
+		{"unknown", nil, false},
+		{"keeping its Wizards", &ai.OpponentProfile{Wizards: 40}, true},
+		{"spending its Wizards early", &ai.OpponentProfile{Wizards: 40, EarlyWizards: 40}, false},
+	} {
+		tutor := ai.NewTutor()
+		tutor.Read([]*ai.OpponentProfile{nil, nil, tc.profile})
+		if warning := tutor.Warn(&view, green(12)); (warning != "") != tc.warned {
+			t.Errorf("after a player %s, warned %q before the Green 12", tc.name, warning)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// WeightsVersion is the version of the weights files this build reads.
+const WeightsVersion = 1
+
+// Weights are the magic numbers of the heuristic bidding and play, so that
+// they can be tuned by self-play and kept in a JSON profile.
+type Weights struct {
+	Version int    `json:"version"`
+	Name    string `json:"name,omitempty"`
+	// How many cards an opponent spends on a single trick, relative to the
+	// number of tricks in the turn
+	SpendRate float64 `json:"spendRate"`
+	// An off-suit card has to be led, or followed to, before it can win
+	OffSuitFactor float64 `json:"offSuitFactor"`
+	// BidScale multiplies the personality's BidFactor
+	BidScale float64 `json:"bidScale"`
+	// TableShading is how much the predictions of the seats that bid first
+	// shade the expected tricks
+	TableShading float64 `json:"tableShading"`
+	// DuckingThreat is how likely an unknown seat that made its prediction
+	// still overtakes a card
+	DuckingThreat float64 `json:"duckingThreat"`
+	// WizardReserve is the tricks a Wizard held back is expected to secure
+	WizardReserve float64 `json:"wizardReserve"`
+}
+
+// DefaultWeights are the hand-picked numbers, used by every agent not given
+// its own.
+var DefaultWeights = Weights{
+	Version:       WeightsVersion,
+	Name:          "default",
+	SpendRate:     2.0,
+	OffSuitFactor: 0.6,
+	BidScale:      1.0,
+	TableShading:  0.5,
+	DuckingThreat: 0.3,
+	WizardReserve: 2.0,
+}
+
+// LoadWeights reads a weights profile saved by Save.
+func LoadWeights(path string) (Weights, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Weights{}, err
+	}
+	var w Weights
+	if err := json.Unmarshal(data, &w); err != nil {
+		return Weights{}, err
+	}
+	if w.Version != WeightsVersion {
+		return Weights{}, fmt.Errorf("%s holds weights of version %d, this build reads version %d", path, w.Version, WeightsVersion)
+	}
+	return w, nil
+}
+
+func (w Weights) Save(path string) error {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -1,10 +1,23 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"math"
+	"math/rand"
+	"os"
+	"slices"
 	"testing"
 	"time"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/card"
+	"wizard/deck"
+	"wizard/engine"
 	"wizard/game"
 	"wizard/player"
 )
@@ -12,12 +25,14 @@ import (
 // AIPerformanceMetrics tracks AI performance across multiple games
 type AIPerformanceMetrics struct {
 	PlayerName           string
+	Profile              string
 	GamesPlayed          int
 	GamesWon             int
 	TotalScore           int
 	BidAccuracy          float64 // Percentage of exact bids
 	BidTendency          float64 // Positive = overbids, Negative = underbids
 	AverageDecisionTime  time.Duration
+	Calibration          time.Duration // the time the machine took over a fixed amount of work, when measured
 	TricksWonVsPredicted map[int]int // [predicted] -> actual_won
 	SpecialCardUsage     SpecialCardStats
 	GameResults          []GameResult
@@ -41,14 +56,19 @@ type GameResult struct {
 	BidsTotal       int
 	AvgBidError     float64
 	GameDuration    time.Duration
+	Bids, Won       []int // prediction and tricks taken of every turn
+	Specials        arena.Specials
+	DecisionTime    time.Duration // average time over a decision
 }
 
 // AITestSuite contains all AI performance tests
 type AITestSuite struct {
 	TestGames       int
 	DifficultyLevel string
+	Profile         string   // AI profile of the player under test
 	Opponents       []string // AI difficulty levels to test against
 	Metrics         map[string]*AIPerformanceMetrics
+	rng             *rand.Rand // seeds every game, so that a run plays the same games
 }
 
 // TestAIBiddingAccuracy tests the AI's ability to predict tricks accurately
@@ -66,17 +86,22 @@ func TestAIBiddingAccuracy(t *testing.T) {
 		{"Strong Wizard Hand", 5, "hearts", 3, 1},
 		{"Weak Joker Hand", 5, "spades", 1, 1},
 		{"Mixed Trump Hand", 7, "clubs", 4, 2},
-		{"No Trump Hand", 3, "diamonds", 1, 1},
+		{"No Trump Hand", 3, "", 1, 1},
 	}
 	
 	for _, scenario := range testScenarios {
 		t.Run(scenario.name, func(t *testing.T) {
-			accuracy := suite.TestBiddingScenario(scenario.handSize, scenario.trumpSuit)
+			accuracy, withinTolerance, meanBid := suite.TestBiddingScenario(scenario.name, scenario.handSize, scenario.trumpSuit, scenario.tolerance)
+			t.Logf("%.0f%% exact, %.0f%% within %d, bidding %.2f", accuracy*100, withinTolerance*100, scenario.tolerance, meanBid)
 			
 			if accuracy < 0.7 { // 70% accuracy threshold
 				t.Errorf("AI bidding accuracy too low: %.2f%% for scenario %s", 
 					accuracy*100, scenario.name)
 			}
+			if math.Abs(meanBid-float64(scenario.expectedBid)) > float64(scenario.tolerance) {
+				t.Errorf("AI bids %.2f on average for scenario %s, expected %d",
+					meanBid, scenario.name, scenario.expectedBid)
+			}
 		})
 	}
 }
@@ -100,6 +125,7 @@ func TestAICardPlayStrategy(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			correctDecisions := suite.TestCardPlayStrategy(test.scenario)
 			
+			t.Logf("%.1f%% correct", correctDecisions*100)
 			if correctDecisions < 0.8 { // 80% correct strategic decisions
 				t.Errorf("AI strategic decisions too low: %.2f%% for %s", 
 					correctDecisions*100, test.name)
@@ -115,8 +141,18 @@ func TestAIDifficultyLevels(t *testing.T) {
 	
 	for i, difficulty := range difficulties {
 		t.Run(fmt.Sprintf("Difficulty_%s", difficulty), func(t *testing.T) {
-			suite := NewAITestSuite(200, difficulty)
+			// An expert game searches every decision: fewer of them are
+			// played, none in short mode
+			games := 200
+			if difficulty == "expert" {
+				if testing.Short() {
+					t.Skip("expert games are slow")
+				}
+				games = 80
+			}
+			suite := NewAITestSuite(games, difficulty)
 			winRate := suite.TestAgainstBaseline()
+			t.Logf("win rate %.3f", winRate)
 			
 			tolerance := 0.1 // ±10% tolerance
 			if math.Abs(winRate-expectedWinRates[i]) > tolerance {
@@ -127,11 +163,26 @@ func TestAIDifficultyLevels(t *testing.T) {
 	}
 }
 
+// updateBaseline has TestAIPerformanceRegression save its run as the
+// baseline instead of comparing with it
+var updateBaseline = flag.Bool("update-baseline", false, "write baseline_performance.json from this run")
+
 // TestAIPerformanceRegression ensures new changes don't hurt performance
 func TestAIPerformanceRegression(t *testing.T) {
 	baseline := LoadBaselineMetrics("baseline_performance.json")
+	if baseline == nil && !*updateBaseline {
+		t.Fatal("no baseline_performance.json to compare with: run the test with -update-baseline")
+	}
 	current := NewAITestSuite(100, "medium")
+	calibration := calibrate()
 	currentMetrics := current.RunFullPerformanceTest()
+	currentMetrics.Calibration = (calibration + calibrate()) / 2
+	if *updateBaseline {
+		if err := SaveBaselineMetrics("baseline_performance.json", currentMetrics); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
 	
 	// Check for significant performance degradation
 	if currentMetrics.BidAccuracy < baseline.BidAccuracy-0.05 {
@@ -139,9 +190,62 @@ func TestAIPerformanceRegression(t *testing.T) {
 			baseline.BidAccuracy*100, currentMetrics.BidAccuracy*100)
 	}
 	
-	if currentMetrics.AverageDecisionTime > time.Duration(float64(baseline.AverageDecisionTime)*1.5) {
-		t.Errorf("Decision time regression: baseline %v, current %v",
-			baseline.AverageDecisionTime, currentMetrics.AverageDecisionTime)
+	// The baseline's decision time as this machine, as loaded now, would
+	// take it
+	expected := baseline.AverageDecisionTime
+	if baseline.Calibration > 0 {
+		expected = time.Duration(float64(expected) * float64(currentMetrics.Calibration) / float64(baseline.Calibration))
+	}
+	if currentMetrics.AverageDecisionTime > time.Duration(float64(expected)*1.5) {
+		t.Errorf("Decision time regression: baseline %v (%v here), current %v",
+			baseline.AverageDecisionTime, expected, currentMetrics.AverageDecisionTime)
+	}
+}
+
+// TestSimulateGame plays games headless and checks their results add up
+func TestSimulateGame(t *testing.T) {
+	suite := NewAITestSuite(4, "medium")
+	metrics := &AIPerformanceMetrics{TricksWonVsPredicted: make(map[int]int)}
+	
+	// Nothing is printed
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	printed := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		printed <- data
+	}()
+	os.Stdout = w
+	for gameNum := range suite.TestGames {
+		suite.updateMetrics(metrics, suite.SimulateGame(gameNum))
+	}
+	os.Stdout = stdout
+	w.Close()
+	if out := <-printed; len(out) > 0 {
+		t.Errorf("the games printed %q", out)
+	}
+	
+	for _, result := range metrics.GameResults {
+		if result.BidsTotal != arena.Turns(4) || len(result.Won) != result.BidsTotal || result.BidsSuccessful > result.BidsTotal {
+			t.Errorf("%d of %d bids made over %d turns", result.BidsSuccessful, result.BidsTotal, len(result.Won))
+		}
+		if result.FinalRank < 1 || result.FinalRank > 4 || result.DecisionTime <= 0 {
+			t.Errorf("rank %d, %v a decision", result.FinalRank, result.DecisionTime)
+		}
+		points := 0
+		for turn, bid := range result.Bids {
+			points += engine.Score(bid, result.Won[turn])
+		}
+		if points != result.FinalScore {
+			t.Errorf("the turns make %d points, the game %d", points, result.FinalScore)
+		}
+	}
+	suite.calculateFinalMetrics(metrics)
+	if metrics.BidAccuracy <= 0 || metrics.BidAccuracy > 1 || metrics.AverageDecisionTime <= 0 {
+		t.Errorf("bid accuracy %.2f, %v a decision", metrics.BidAccuracy, metrics.AverageDecisionTime)
 	}
 }
 
@@ -156,13 +260,18 @@ func BenchmarkAIDecisionSpeed(b *testing.B) {
 	}
 }
 
+// suiteSeed seeds the games of every suite
+const suiteSeed = 2024
+
 // NewAITestSuite creates a new test suite with specified parameters
 func NewAITestSuite(games int, difficulty string) *AITestSuite {
 	return &AITestSuite{
 		TestGames:       games,
 		DifficultyLevel: difficulty,
+		Profile:         "balanced",
 		Opponents:       []string{"easy", "medium", "hard"},
 		Metrics:         make(map[string]*AIPerformanceMetrics),
+		rng:             rand.New(rand.NewSource(suiteSeed)),
 	}
 }
 
@@ -172,6 +281,7 @@ func (suite *AITestSuite) RunFullPerformanceTest() *AIPerformanceMetrics {
 	
 	metrics := &AIPerformanceMetrics{
 		PlayerName:           fmt.Sprintf("AI_%s", suite.DifficultyLevel),
+		Profile:              suite.Profile,
 		TricksWonVsPredicted: make(map[int]int),
 		GameResults:          make([]GameResult, 0, suite.TestGames),
 	}
@@ -197,42 +307,67 @@ func (suite *AITestSuite) SimulateGame(gameNum int) GameResult {
 	
 	// Create players: 1 AI under test + 3 opponents
 	playerNames := []string{} // No human players
-	players := player.Register(playerNames, 4)
+	players := player.Register(playerNames, 4, suite.Profile)
 	
 	// Set AI difficulty for the player under test
 	players[0].Name = fmt.Sprintf("TestAI_%s", suite.DifficultyLevel)
+	players[0].Level = suite.DifficultyLevel
 	
-	// Initialize and run game
-	testGame := game.InitGame(players)
-	testGame.Run(0) // Start with player 0 as dealer
+	// Initialize and run game, the deal moving on from game to game
+	testGame := game.InitSeededGame(players, suite.rng.Int63())
+	outcome, err := testGame.Simulate(int16(gameNum % len(players)))
+	if err != nil {
+		panic(err)
+	}
 	
 	// Collect game results
 	result := GameResult{
 		FinalScore:   players[0].Score,
-		FinalRank:    suite.calculateRank(players, 0),
+		FinalRank:    outcome.Ranks[0],
 		GameDuration: time.Since(startTime),
+		Specials:     outcome.Specials[0],
+		DecisionTime: outcome.DecisionTime(0),
 	}
+	bidError := 0
+	for turn := range outcome.Bids {
+		bid, won := outcome.Bids[turn][0], outcome.Won[turn][0]
+		result.Bids = append(result.Bids, bid)
+		result.Won = append(result.Won, won)
+		result.BidsTotal++
+		if bid == won {
+			result.BidsSuccessful++
+		}
+		bidError += bid - won
+	}
+	result.AvgBidError = float64(bidError) / float64(result.BidsTotal)
 	
 	return result
 }
 
-// TestBiddingScenario tests AI bidding in specific card scenarios
-func (suite *AITestSuite) TestBiddingScenario(handSize int, trumpSuit string) float64 {
-	correctBids := 0
+// TestBiddingScenario tests AI bidding in specific card scenarios: the share
+// of bids the turn played out makes exactly, the share it misses by tolerance
+// tricks at most, and the average bid.
+func (suite *AITestSuite) TestBiddingScenario(scenario string, handSize int, trumpSuit string, tolerance int) (float64, float64, float64) {
+	correctBids, closeBids, totalBid := 0, 0, 0
 	totalTests := 50
 	
 	for i := 0; i < totalTests; i++ {
 		// Create controlled hand scenario
-		hand := suite.generateTestHand(handSize, trumpSuit)
-		actualBid := suite.getAIBid(hand, trumpSuit)
-		expectedTricks := suite.simulateHandOutcome(hand, trumpSuit)
+		hand := suite.generateTestHand(scenario, handSize, trumpSuit)
+		state, agents := suite.dealTestHand(hand, trumpSuit)
+		actualBid := suite.getAIBid(state, agents)
+		tricks := suite.simulateHandOutcome(state, agents)
 		
-		if actualBid == expectedTricks {
+		totalBid += actualBid
+		if actualBid == tricks {
 			correctBids++
 		}
+		if actualBid-tricks <= tolerance && tricks-actualBid <= tolerance {
+			closeBids++
+		}
 	}
 	
-	return float64(correctBids) / float64(totalTests)
+	return float64(correctBids) / float64(totalTests), float64(closeBids) / float64(totalTests), float64(totalBid) / float64(totalTests)
 }
 
 // TestCardPlayStrategy evaluates AI's strategic card play
@@ -243,9 +378,9 @@ func (suite *AITestSuite) TestCardPlayStrategy(scenario string) float64 {
 	for i := 0; i < totalDecisions; i++ {
 		gameState := suite.generateGameState(scenario)
 		aiChoice := suite.getAICardChoice(gameState)
-		optimalChoice := suite.getOptimalChoice(gameState)
+		optimalChoice := suite.getOptimalChoice(gameState, scenario)
 		
-		if suite.isStrategicallyCorrect(aiChoice, optimalChoice, scenario) {
+		if suite.isStrategicallyCorrect(aiChoice, optimalChoice) {
 			correctDecisions++
 		}
 	}
@@ -276,29 +411,46 @@ func (suite *AITestSuite) updateMetrics(metrics *AIPerformanceMetrics, result Ga
 	if result.FinalRank == 1 {
 		metrics.GamesWon++
 	}
+	for turn, bid := range result.Bids {
+		metrics.TricksWonVsPredicted[bid] += result.Won[turn]
+	}
+	usage := &metrics.SpecialCardUsage
+	usage.WizardsPlayed += result.Specials.Wizards
+	usage.WizardsWonTricks += result.Specials.WizardsWon
+	usage.JokersPlayed += result.Specials.Jokers
+	usage.JokersLostTricks += result.Specials.JokersLost
+	usage.WizardTiming = append(usage.WizardTiming, result.Specials.WizardTiming...)
+	usage.JokerTiming = append(usage.JokerTiming, result.Specials.JokerTiming...)
 }
 
 func (suite *AITestSuite) calculateFinalMetrics(metrics *AIPerformanceMetrics) {
 	if metrics.GamesPlayed > 0 {
-		// Calculate bid accuracy, tendency, etc.
+		// Calculate bid accuracy, tendency, etc. over every bid
 		totalBidError := 0.0
-		exactBids := 0
+		exactBids, totalBids := 0, 0
+		var decisionTime time.Duration
 		
 		for _, result := range metrics.GameResults {
 			if result.BidsTotal > 0 {
 				exactBids += result.BidsSuccessful
-				totalBidError += result.AvgBidError
+				totalBids += result.BidsTotal
+				totalBidError += result.AvgBidError * float64(result.BidsTotal)
 			}
+			decisionTime += result.DecisionTime
 		}
 		
-		metrics.BidAccuracy = float64(exactBids) / float64(metrics.GamesPlayed)
-		metrics.BidTendency = totalBidError / float64(metrics.GamesPlayed)
+		if totalBids > 0 {
+			metrics.BidAccuracy = float64(exactBids) / float64(totalBids)
+			metrics.BidTendency = totalBidError / float64(totalBids)
+		}
+		metrics.AverageDecisionTime = decisionTime / time.Duration(metrics.GamesPlayed)
 	}
 }
 
 func (suite *AITestSuite) printPerformanceReport(metrics *AIPerformanceMetrics) {
 	fmt.Printf("\n=== AI Performance Report ===\n")
 	fmt.Printf("Player: %s\n", metrics.PlayerName)
+	fmt.Printf("Profile: %s\n", metrics.Profile)
 	fmt.Printf("Games Played: %d\n", metrics.GamesPlayed)
 	fmt.Printf("Win Rate: %.2f%%\n", float64(metrics.GamesWon)/float64(metrics.GamesPlayed)*100)
 	fmt.Printf("Average Score: %.1f\n", float64(metrics.TotalScore)/float64(metrics.GamesPlayed))
@@ -308,48 +460,316 @@ func (suite *AITestSuite) printPerformanceReport(metrics *AIPerformanceMetrics)
 	fmt.Printf("===========================\n")
 }
 
-// Placeholder implementations - replace with actual game logic
-func (suite *AITestSuite) generateTestHand(handSize int, trumpSuit string) []interface{} {
-	return make([]interface{}, handSize) // Implement based on card.Card
+// suitSymbols gives the symbols the suits of the scenarios stand for
+var suitSymbols = map[string]card.Symbol{"hearts": card.Red, "spades": card.Blue, "clubs": card.Green, "diamonds": card.Yellow}
+
+// generateTestHand deals a hand of the scenario, worth the tricks it is
+// expected to bid: two Wizards and the top trump, two Jokers, a Wizard and
+// the three top trumps, or a thirteen with no trump at all, the rest made up
+// of low cards of the other symbols
+func (suite *AITestSuite) generateTestHand(scenario string, handSize int, trumpSuit string) []card.Card {
+	trump := suitSymbols[trumpSuit]
+	pool := deck.InitDeck()
+	pool.ShuffleWith(suite.rng)
+
+	var hand []card.Card
+	take := func(n int, fits func(c card.Card) bool) {
+		for i := 0; i < len(pool) && n > 0; {
+			if fits(pool[i]) {
+				hand = append(hand, pool[i])
+				pool = slices.Delete(pool, i, i+1)
+				n--
+			} else {
+				i++
+			}
+		}
+	}
+	trumps := func(lowest int) func(c card.Card) bool {
+		return func(c card.Card) bool { return !c.IsWizard && !c.IsJoker && c.Symbol == trump && c.Number >= lowest }
+	}
+	offSuit := func(highest int) func(c card.Card) bool {
+		return func(c card.Card) bool { return !c.IsWizard && !c.IsJoker && c.Symbol != trump && c.Number <= highest }
+	}
+
+	switch scenario {
+	case "Strong Wizard Hand":
+		take(2, func(c card.Card) bool { return c.IsWizard })
+		take(1, trumps(13))
+		take(handSize-len(hand), offSuit(3))
+	case "Weak Joker Hand":
+		take(2, func(c card.Card) bool { return c.IsJoker })
+		take(handSize-len(hand), offSuit(6))
+	case "Mixed Trump Hand":
+		take(1, func(c card.Card) bool { return c.IsWizard })
+		take(3, trumps(11))
+		take(handSize-len(hand), offSuit(3))
+	case "No Trump Hand":
+		take(1, func(c card.Card) bool { return !c.IsWizard && !c.IsJoker && c.Number == 13 })
+		take(handSize-len(hand), offSuit(3))
+	}
+	take(handSize-len(hand), offSuit(13))
+	return hand
 }
 
-func (suite *AITestSuite) getAIBid(hand []interface{}, trumpSuit string) int {
-	return 0 // Implement AI bidding logic
+// dealTestHand deals hand to the first seat of a table of four, with a card
+// of trumpSuit turned up, or a Joker for no trump; the first seat bids first
+func (suite *AITestSuite) dealTestHand(hand []card.Card, trumpSuit string) (*engine.State, []ai.Agent) {
+	rest := deck.InitDeck()
+	for _, c := range hand {
+		i := slices.Index(rest, c)
+		rest = slices.Delete(rest, i, i+1)
+	}
+	rest.ShuffleWith(suite.rng)
+	up := slices.IndexFunc(rest, func(c card.Card) bool {
+		if trumpSuit == "" {
+			return c.IsJoker
+		}
+		return !c.IsWizard && !c.IsJoker && c.Symbol == suitSymbols[trumpSuit]
+	})
+	turnedUp := rest[up]
+	rest = slices.Delete(rest, up, up+1)
+	// The Wizards and Jokers the hand doesn't hold stay in the stock, so the
+	// scenario's tricks are those of its hand
+	special := func(c card.Card) int {
+		if c.IsWizard || c.IsJoker {
+			return 1
+		}
+		return 0
+	}
+	slices.SortStableFunc(rest, func(a, b card.Card) int { return special(a) - special(b) })
+
+	// The deck is drawn from its end: the first seat's hand comes last,
+	// the turned-up card right before the others' hands
+	others := 3 * len(hand)
+	turnDeck := append(slices.Clone(rest[others:]), turnedUp)
+	turnDeck = append(turnDeck, rest[:others]...)
+	turnDeck = append(turnDeck, hand...)
+	state := engine.NewState(4, 3, len(hand), turnDeck)
+	return state, suite.seatAgents(state)
 }
 
-func (suite *AITestSuite) simulateHandOutcome(hand []interface{}, trumpSuit string) int {
-	return 0 // Implement hand simulation
+// seatAgents gives every seat of state an agent of the suite's level
+// following the turn
+func (suite *AITestSuite) seatAgents(state *engine.State) []ai.Agent {
+	level, _ := ai.LevelByName(suite.DifficultyLevel)
+	personality, _ := ai.PersonalityByName(suite.Profile)
+	agents := make([]ai.Agent, state.NumPlayers())
+	for seat := range agents {
+		agents[seat] = ai.New(level, personality, suite.rng.Int63())
+		if observer, ok := agents[seat].(ai.Observer); ok {
+			state.Observe(seat, observer.Observe)
+		}
+	}
+	return agents
 }
 
-func (suite *AITestSuite) generateGameState(scenario string) interface{} {
-	return nil // Implement game state generation
+// getAIBid has the first seat bid
+func (suite *AITestSuite) getAIBid(state *engine.State, agents []ai.Agent) int {
+	suite.decide(state, agents)
+	return state.Bids[0]
 }
 
-func (suite *AITestSuite) getAICardChoice(gameState interface{}) interface{} {
-	return nil // Implement AI card selection
+// simulateHandOutcome plays the rest of the turn and returns the tricks the
+// first seat took
+func (suite *AITestSuite) simulateHandOutcome(state *engine.State, agents []ai.Agent) int {
+	for state.Phase != engine.Done {
+		suite.decide(state, agents)
+	}
+	return state.Won[0]
 }
 
-func (suite *AITestSuite) getOptimalChoice(gameState interface{}) interface{} {
-	return nil // Implement optimal play calculation
+// decide plays the decision of the seat to act, the first legal one when the
+// agent's breaks the rules
+func (suite *AITestSuite) decide(state *engine.State, agents []ai.Agent) {
+	if err := state.Apply(arena.Decide(agents[state.ToAct], state)); err != nil {
+		state.Apply(state.LegalActions()[0])
+	}
 }
 
-func (suite *AITestSuite) isStrategicallyCorrect(aiChoice, optimal interface{}, scenario string) bool {
-	return true // Implement strategic correctness check
+// playPosition is a card the first seat has to play, with the agent that
+// followed the turn up to it
+type playPosition struct {
+	state *engine.State
+	agent ai.Agent
 }
 
+// generateGameState plays turns between agents of the suite's level until
+// the first seat has to play in the scenario's position
+func (suite *AITestSuite) generateGameState(scenario string) playPosition {
+	for {
+		turnDeck := deck.InitDeck()
+		turnDeck.ShuffleWith(suite.rng)
+		state := engine.NewState(4, suite.rng.Intn(4), 3+suite.rng.Intn(8), turnDeck)
+		agents := suite.seatAgents(state)
+		for state.Phase != engine.Done {
+			switch {
+			case state.Phase == engine.Playing && state.ToAct == 0 && suite.inScenario(state, scenario):
+				return playPosition{state: state, agent: agents[0]}
+			case state.Phase == engine.Bidding && state.ToAct == 0 && scenario == "ahead_on_bid":
+				// Over a bid of nothing from the first trick taken on
+				if state.Apply(engine.BidOf(0)) != nil {
+					suite.decide(state, agents)
+				}
+			default:
+				suite.decide(state, agents)
+			}
+		}
+	}
+}
+
+// inScenario reports whether the first seat, to play, is in the scenario's
+// position with a choice that matters: short of its bid with every trick
+// left needed, over it, on it, or with two cards left and a trick still needed
+func (suite *AITestSuite) inScenario(state *engine.State, scenario string) bool {
+	view := state.View(0)
+	need := view.Need(0)
+	switch scenario {
+	case "behind_on_bid":
+		if len(view.Trick.Cards) == 0 || need <= 0 || need < view.TricksLeft() {
+			return false
+		}
+	case "ahead_on_bid":
+		if len(view.Trick.Cards) == 0 || need >= 0 {
+			return false
+		}
+	case "exact_bid":
+		if len(view.Trick.Cards) == 0 || need != 0 {
+			return false
+		}
+	case "final_trick":
+		if len(view.Hand) != 2 || need <= 0 {
+			return false
+		}
+	}
+	optimal := suite.getOptimalChoice(playPosition{state: state}, scenario)
+	return len(optimal) > 0 && len(optimal) < len(view.Trick.LegalPlays(view.Hand))
+}
+
+func (suite *AITestSuite) getAICardChoice(gameState playPosition) card.Card {
+	return arena.Decide(gameState.agent, gameState.state).Card
+}
+
+// getOptimalChoice returns the right cards for the scenario: those taking
+// the trick short of the bid, those losing it once the bid is made or lost,
+// and with the last cards those the expert's judgement scores best
+func (suite *AITestSuite) getOptimalChoice(gameState playPosition, scenario string) []card.Card {
+	view := gameState.state.View(0)
+	var winners, losers []card.Card
+	for _, c := range view.Trick.LegalPlays(view.Hand) {
+		if view.Trick.Beats(c) {
+			winners = append(winners, c)
+		} else {
+			losers = append(losers, c)
+		}
+	}
+
+	switch scenario {
+	case "behind_on_bid":
+		return winners
+	case "ahead_on_bid", "exact_bid":
+		return losers
+	}
+	moves, scores := ai.Evaluate(&view, ai.Search{Iterations: lastCardsDeals}, suite.rng.Int63())
+	best := slices.Max(scores)
+	var optimal []card.Card
+	for i, move := range moves {
+		if scores[i] >= best-1 {
+			optimal = append(optimal, move.Card)
+		}
+	}
+	return optimal
+}
+
+// lastCardsDeals is the number of deals the last cards are judged over; the
+// cards within a point of the best are as good
+const lastCardsDeals = 500
+
+func (suite *AITestSuite) isStrategicallyCorrect(aiChoice card.Card, optimal []card.Card) bool {
+	return slices.Contains(optimal, aiChoice)
+}
+
+// humanLevelTable stands in for human-level play: a casual table with one
+// beginner and two intermediate players
+var humanLevelTable = []string{"easy", "medium", "medium"}
+
 func (suite *AITestSuite) playAgainstBaseline() bool {
-	return true // Implement baseline comparison
+	players := player.Register([]string{}, len(humanLevelTable)+1, suite.Profile)
+	players[0].Level = suite.DifficultyLevel
+	for i, opponent := range humanLevelTable {
+		players[i+1].Level = opponent
+	}
+
+	testGame := game.InitSeededGame(players, suite.rng.Int63())
+	if _, err := testGame.Simulate(int16(suite.rng.Intn(len(players)))); err != nil {
+		panic(err)
+	}
+
+	return suite.calculateRank(players, 0) == 1
 }
 
 func (suite *AITestSuite) calculateRank(players player.Players, playerIndex int) int {
-	return 1 // Implement ranking calculation
+	rank := 1
+	for _, p := range players {
+		if p.Score > players[playerIndex].Score {
+			rank++
+		}
+	}
+	return rank
 }
 
+// BenchmarkDecisionTime plays a turn of five cards between agents of the
+// suite's level, one per seat of a game
 func (suite *AITestSuite) BenchmarkDecisionTime() {
-	// Implement decision timing benchmark
+	level, _ := ai.LevelByName(suite.DifficultyLevel)
+	personality, _ := ai.PersonalityByName(suite.Profile)
+	agents := make([]ai.Agent, len(suite.Opponents)+1)
+	for seat := range agents {
+		agents[seat] = ai.New(level, personality, int64(seat))
+	}
+	arena.Turn(agents, 0, 5, rand.New(rand.NewSource(1)), nil)
+}
+
+// calibrate times turns played on the engine alone, always the first legal
+// move, for comparing decision times taken on machines of different speed
+// or under different loads
+func calibrate() time.Duration {
+	start := time.Now()
+	for i := range 2000 {
+		turnDeck := deck.InitDeck()
+		turnDeck.ShuffleWith(rand.New(rand.NewSource(int64(i))))
+		state := engine.NewState(4, i%4, 1+i%15, turnDeck)
+		for state.Phase != engine.Done {
+			state.Apply(state.LegalActions()[0])
+		}
+	}
+	return time.Since(start)
+}
+
+// SaveBaselineMetrics writes metrics as JSON, leaving out the results of
+// every game
+func SaveBaselineMetrics(filename string, metrics *AIPerformanceMetrics) error {
+	baseline := *metrics
+	baseline.GameResults = nil
+	baseline.SpecialCardUsage.WizardTiming, baseline.SpecialCardUsage.JokerTiming = nil, nil
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0o644)
 }
 
+// LoadBaselineMetrics reads metrics saved as JSON, nil without the file
 func LoadBaselineMetrics(filename string) *AIPerformanceMetrics {
-	// Implement baseline loading from file
-	return &AIPerformanceMetrics{}
-}
\ No newline at end of file
+	metrics := &AIPerformanceMetrics{}
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal(data, metrics); err != nil {
+		panic(fmt.Errorf("%s: %w", filename, err))
+	}
+	return metrics
+}
@@ -0,0 +1,190 @@
+// Package arena plays whole games between agents without any console I/O, so
+// that they can be compared and tuned on many games.
+package arena
+
+import (
+	"math/rand"
+	"slices"
+	"time"
+	"wizard/ai"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// Result is what came of a game for every seat.
+type Result struct {
+	Scores []int   // final score
+	Ranks  []int   // 1 for the best score, seats on the same score share a rank
+	Bids   [][]int // prediction of every turn
+	Won    [][]int // tricks taken in every turn
+	// Specials is how every seat used its Wizards and Jokers
+	Specials []Specials
+	// Thinking is the time every seat took over its decisions, Decisions
+	// how many it took
+	Thinking  []time.Duration
+	Decisions []int
+	// Events is the game's log, as observed by engine.Everyone
+	Events []engine.Event
+}
+
+// Specials is how a seat used its Wizards and Jokers.
+type Specials struct {
+	Wizards    int // Wizards played
+	WizardsWon int // of them, the ones that took the trick
+	Jokers     int // Jokers played
+	JokersLost int // of them, the ones that lost the trick
+	// when every Wizard and Joker was played in its turn, from 0 on the
+	// first trick to 1 on the last
+	WizardTiming []float64
+	JokerTiming  []float64
+}
+
+// DecisionTime is the average time seat took over a decision.
+func (r Result) DecisionTime(seat int) time.Duration {
+	if r.Decisions[seat] == 0 {
+		return 0
+	}
+	return r.Thinking[seat] / time.Duration(r.Decisions[seat])
+}
+
+// Turns is the number of turns of a game: the whole deck is dealt in the last.
+func Turns(numPlayers int) int {
+	return 60 / numPlayers
+}
+
+// Play plays a whole game between the agents, one per seat. The first turn is
+// dealt by dealer and the deal moves round the table; every deck is shuffled
+// with rng, so a game is played again with the same seed and agents. The
+// agents playing for the win are told the standings before every turn.
+func Play(agents []ai.Agent, dealer int, rng *rand.Rand) Result {
+	numPlayers := len(agents)
+	result := Result{Scores: make([]int, numPlayers), Thinking: make([]time.Duration, numPlayers), Decisions: make([]int, numPlayers)}
+
+	for i := range Turns(numPlayers) {
+		for _, agent := range agents {
+			if contender, ok := agent.(ai.Contender); ok {
+				contender.Standings(ai.Standings{Scores: slices.Clone(result.Scores), TurnsLeft: Turns(numPlayers) - i - 1})
+			}
+		}
+		state := turn(agents, (dealer+i)%numPlayers, i+1, rng, func(e engine.Event) {
+			result.Events = append(result.Events, e)
+		}, &result)
+		for seat, points := range state.Scores() {
+			result.Scores[seat] += points
+		}
+		result.Bids = append(result.Bids, state.Bids)
+		result.Won = append(result.Won, state.Won)
+	}
+	result.Ranks = Ranks(result.Scores)
+	result.Specials = SpecialUsage(result.Events, numPlayers)
+	return result
+}
+
+// Turn deals handSize cards and plays the turn out between the agents. log,
+// when not nil, sees every event of the turn.
+func Turn(agents []ai.Agent, dealer, handSize int, rng *rand.Rand, log func(engine.Event)) *engine.State {
+	return turn(agents, dealer, handSize, rng, log, nil)
+}
+
+// turn plays a turn as Turn does, timing the decisions of every seat into
+// result when it is not nil.
+func turn(agents []ai.Agent, dealer, handSize int, rng *rand.Rand, log func(engine.Event), result *Result) *engine.State {
+	turnDeck := deck.InitDeck()
+	turnDeck.ShuffleWith(rng)
+
+	state := engine.NewState(len(agents), dealer, handSize, turnDeck)
+	if log != nil {
+		state.Observe(engine.Everyone, log)
+	}
+	for seat, agent := range agents {
+		if observer, ok := agent.(ai.Observer); ok {
+			state.Observe(seat, observer.Observe)
+		}
+	}
+
+	for state.Phase != engine.Done {
+		// A decision the rules don't allow is replaced by the first legal
+		// one, so that every game comes to an end
+		seat, start := state.ToAct, time.Now()
+		action := Decide(agents[seat], state)
+		if result != nil {
+			result.Thinking[seat] += time.Since(start)
+			result.Decisions[seat]++
+		}
+		if err := state.Apply(action); err != nil {
+			state.Apply(state.LegalActions()[0])
+		}
+	}
+	return state
+}
+
+// Ranks ranks the seats by score, 1 for the best; seats on the same score
+// share the better rank.
+func Ranks(scores []int) []int {
+	ranks := make([]int, len(scores))
+	for seat, score := range scores {
+		ranks[seat] = 1
+		for _, other := range scores {
+			if other > score {
+				ranks[seat]++
+			}
+		}
+	}
+	return ranks
+}
+
+// SpecialUsage goes through the events of a game, as observed by
+// engine.Everyone, for the Wizards and Jokers every seat played.
+func SpecialUsage(events []engine.Event, numPlayers int) []Specials {
+	specials := make([]Specials, numPlayers)
+	var played []engine.Event
+	trick := 0
+	for _, e := range events {
+		switch e.Kind {
+		case engine.DealEvent:
+			if e.Seat == 0 {
+				trick = 0
+			}
+		case engine.PlayEvent:
+			played = append(played, e)
+		case engine.TrickEvent:
+			// 0 on the first trick of the turn, 1 on the last
+			timing := 0.0
+			if e.HandSize > 1 {
+				timing = float64(trick) / float64(e.HandSize-1)
+			}
+			for _, p := range played {
+				s := &specials[p.Seat]
+				switch {
+				case p.Card.IsWizard:
+					s.Wizards++
+					s.WizardTiming = append(s.WizardTiming, timing)
+					if p.Seat == e.Seat {
+						s.WizardsWon++
+					}
+				case p.Card.IsJoker:
+					s.Jokers++
+					s.JokerTiming = append(s.JokerTiming, timing)
+					if p.Seat != e.Seat {
+						s.JokersLost++
+					}
+				}
+			}
+			played = played[:0]
+			trick++
+		}
+	}
+	return specials
+}
+
+// Decide asks agent for the decision of the seat to act.
+func Decide(agent ai.Agent, state *engine.State) engine.Action {
+	view := state.View(state.ToAct)
+	switch state.Phase {
+	case engine.Choosing:
+		return engine.TrumpOf(agent.Trump(&view))
+	case engine.Bidding:
+		return engine.BidOf(agent.Bid(&view))
+	}
+	return engine.PlayOf(agent.Play(&view))
+}
@@ -0,0 +1,61 @@
+package arena_test
+
+import (
+	"math/rand"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/engine"
+)
+
+// TestPlay checks every Wizard and Joker dealt is counted once, and that the
+// ranks follow the scores
+func TestPlay(t *testing.T) {
+	agents := []ai.Agent{ai.New(ai.Easy, ai.Balanced, 1), ai.New(ai.Medium, ai.Balanced, 2), ai.New(ai.Hard, ai.Balanced, 3)}
+	result := arena.Play(agents, 0, rand.New(rand.NewSource(4)))
+	if len(result.Bids) != arena.Turns(3) || len(result.Won) != arena.Turns(3) {
+		t.Fatalf("%d bids and %d tricks taken for %d turns", len(result.Bids), len(result.Won), arena.Turns(3))
+	}
+
+	wizards, jokers := 0, 0
+	for _, e := range result.Events {
+		if e.Kind == engine.DealEvent {
+			for _, c := range e.Hand {
+				if c.IsWizard {
+					wizards++
+				} else if c.IsJoker {
+					jokers++
+				}
+			}
+		}
+	}
+	for seat, s := range result.Specials {
+		wizards -= s.Wizards
+		jokers -= s.Jokers
+		if s.WizardsWon > s.Wizards || s.JokersLost > s.Jokers || len(s.WizardTiming) != s.Wizards || len(s.JokerTiming) != s.Jokers {
+			t.Errorf("seat %d used its specials as %+v", seat, s)
+		}
+		if result.Decisions[seat] == 0 || result.DecisionTime(seat) <= 0 {
+			t.Errorf("seat %d took %d decisions in %v", seat, result.Decisions[seat], result.Thinking[seat])
+		}
+	}
+	if wizards != 0 || jokers != 0 {
+		t.Errorf("%d Wizards and %d Jokers dealt were not counted", wizards, jokers)
+	}
+
+	for _, test := range []struct {
+		scores, ranks []int
+	}{
+		{[]int{30, 10, 20}, []int{1, 3, 2}},
+		{[]int{30, 30, -10}, []int{1, 1, 3}},
+		{[]int{0, 50, 50, 40}, []int{4, 1, 1, 3}},
+	} {
+		ranks := arena.Ranks(test.scores)
+		for seat := range ranks {
+			if ranks[seat] != test.ranks[seat] {
+				t.Errorf("scores %v rank %v, want %v", test.scores, ranks, test.ranks)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"wizard/ai"
+)
+
+// runBidTables generates the bid tables and saves them.
+func runBidTables(args []string) {
+	flags := flag.NewFlagSet("bid-tables", flag.ExitOnError)
+	playerList := flags.String("players", "3,4,5,6", "comma separated numbers of players to generate tables for")
+	deals := flags.Int("deals", 2000, "deals played for every number of players and hand size")
+	minSamples := flags.Int("min-samples", 20, "hands a pattern must be seen in to be kept")
+	seed := flags.Int64("seed", 1, "seed of the deals; the same seed generates the same tables")
+	out := flags.String("out", "bidtables.gz", "file the tables are saved to")
+	flags.Parse(args)
+
+	var players []int
+	for _, field := range strings.Split(*playerList, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 3 || n > 6 {
+			fmt.Fprintln(os.Stderr, "a game is played by 3 to 6 players")
+			os.Exit(2)
+		}
+		players = append(players, n)
+	}
+
+	start := time.Now()
+	table := ai.GenerateBidTable(players, *deals, *minSamples, *seed)
+	if err := table.Save(*out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	info, _ := os.Stat(*out)
+	fmt.Printf("%d patterns from %d deals per hand size, saved to %s (%d kB) in %v\n", len(table.Patterns), *deals, *out, info.Size()/1024, time.Since(start).Round(time.Second))
+}
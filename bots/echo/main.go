@@ -0,0 +1,19 @@
+// Echo is the reference bot of the protocol: it plays the first legal move of
+// every decision. Seat it with
+//
+//	go build -o echobot ./bots/echo
+//	wizard -bots ./echobot
+package main
+
+import (
+	"fmt"
+	"os"
+	"wizard/protocol"
+)
+
+func main() {
+	if err := protocol.Echo(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
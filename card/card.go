@@ -1,6 +1,10 @@
 package card
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/fatih/color"
 )
 
@@ -15,6 +19,11 @@ const (
 
 var Symbols = []Symbol{Blue, Green, Red, Yellow}
 
+// Initial is the first letter of the symbol, as the cards are written.
+func (s Symbol) Initial() string {
+	return string(s[:1])
+}
+
 type Card struct {
 	IsJoker  bool
 	IsWizard bool
@@ -51,3 +60,70 @@ func (c Card) Show() {
 	format.Printf("[%d, %s]", c.Number, c.Symbol)
 
 }
+
+// String is the short name of the card, as written in the logs and the bot
+// protocol: W for a Wizard, J for a Joker, otherwise the initial of the
+// symbol followed by the number, as in E13 for the 13 of Elves. No card at
+// all, as the trump of the last turn, is a dash.
+func (c Card) String() string {
+	switch {
+	case c == (Card{}):
+		return "-"
+	case c.IsWizard:
+		return "W"
+	case c.IsJoker:
+		return "J"
+	}
+	return fmt.Sprintf("%s%d", c.Symbol.Initial(), c.Number)
+}
+
+// Parse reads the short name of a card, in any case.
+func Parse(name string) (Card, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	switch name {
+	case "W":
+		return Card{IsWizard: true, Number: -1}, nil
+	case "J":
+		return Card{IsJoker: true, Number: -1}, nil
+	}
+	if len(name) >= 2 {
+		symbol, err := ParseSymbol(name[:1])
+		number, numErr := strconv.Atoi(name[1:])
+		if err == nil && numErr == nil && number >= 1 && number <= 13 {
+			return Card{Number: number, Symbol: symbol}, nil
+		}
+	}
+	return Card{}, fmt.Errorf("'%s' is not a card", name)
+}
+
+// ParseSymbol reads a symbol from its name or its initial, in any case.
+func ParseSymbol(name string) (Symbol, error) {
+	name = strings.TrimSpace(name)
+	for _, symbol := range Symbols {
+		if strings.EqualFold(string(symbol), name) || strings.EqualFold(symbol.Initial(), name) {
+			return symbol, nil
+		}
+	}
+	return "", fmt.Errorf("'%s' is not a symbol", name)
+}
+
+// MarshalText writes the short name of the card, empty for no card.
+func (c Card) MarshalText() ([]byte, error) {
+	if c == (Card{}) {
+		return nil, nil
+	}
+	return []byte(c.String()), nil
+}
+
+func (c *Card) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*c = Card{}
+		return nil
+	}
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
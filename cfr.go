@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"wizard/ai"
+	"wizard/cfr"
+)
+
+// runCFR computes the strategy tables of the small turns, saves them and
+// reports how exploitable they and the built-in levels are.
+func runCFR(args []string) {
+	flags := flag.NewFlagSet("cfr", flag.ExitOnError)
+	playerList := flags.String("players", "3,4,5,6", "comma separated numbers of players to compute tables for")
+	hands := flags.Int("hands", ai.MaxStrategyHand, "largest hand size to compute tables for")
+	iterations := flags.Int("iterations", 20000, "iterations per table; 0 to only report on the saved strategy")
+	against := flags.String("against", "medium", "comma separated levels whose exploitability is reported next to the strategy's")
+	responses := flags.Int("response-iterations", 5000, "iterations learning the best response of the exploitability")
+	deals := flags.Int("deals", 2000, "deals the best response is measured on")
+	seed := flags.Int64("seed", 1, "seed of the deals")
+	out := flags.String("out", "strategy.gz", "file the strategy is saved to, or read from with no iterations")
+	flags.Parse(args)
+
+	var players []int
+	for _, field := range strings.Split(*playerList, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 3 || n > 6 {
+			fmt.Fprintln(os.Stderr, "a game is played by 3 to 6 players")
+			os.Exit(2)
+		}
+		players = append(players, n)
+	}
+	if *hands < 1 || *hands > ai.MaxStrategyHand {
+		fmt.Fprintf(os.Stderr, "tables are computed for hands of 1 to %d cards\n", ai.MaxStrategyHand)
+		os.Exit(2)
+	}
+	var levels []ai.Level
+	for _, name := range strings.Split(*against, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		level, err := ai.LevelByName(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		levels = append(levels, level)
+	}
+
+	strategy := ai.NewStrategy()
+	if *iterations > 0 {
+		for _, n := range players {
+			for handSize := 1; handSize <= *hands; handSize++ {
+				trainer := cfr.NewTrainer(n, handSize, *seed)
+				trainer.Run(*iterations)
+				trainer.Export(strategy)
+				fmt.Printf("%-20s %8d iterations %8d information sets\n", ai.StrategyTable(n, handSize), *iterations, trainer.InfoSets())
+			}
+		}
+		if err := strategy.Save(*out); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		info, _ := os.Stat(*out)
+		fmt.Printf("Saved %d information sets to %s (%d kB)\n\n", len(strategy.Tables), *out, info.Size()/1024)
+	} else {
+		var err error
+		if strategy, err = ai.LoadStrategy(*out); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Println("Exploitability: points per turn a best response gains against every other seat")
+	fmt.Printf("%-20s", "table")
+	fmt.Printf(" %16s", "strategy")
+	for _, level := range levels {
+		fmt.Printf(" %16s", level)
+	}
+	fmt.Println()
+	for _, n := range players {
+		for handSize := 1; handSize <= *hands; handSize++ {
+			fmt.Printf("%-20s", ai.StrategyTable(n, handSize))
+			report := func(profile cfr.Policy) {
+				gain, err := cfr.Exploitability(n, handSize, profile, *responses, *deals, *seed)
+				fmt.Printf(" %16s", fmt.Sprintf("%.2f ± %.2f", gain, err))
+			}
+			report(cfr.StrategyPolicy(strategy, *seed))
+			for _, level := range levels {
+				report(cfr.AgentPolicy(ai.New(level, ai.Balanced, *seed)))
+			}
+			fmt.Println()
+		}
+	}
+}
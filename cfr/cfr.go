@@ -0,0 +1,170 @@
+// Package cfr computes near-equilibrium strategies of the small turns by
+// external sampling Monte Carlo counterfactual regret minimisation, over the
+// information sets of ai.InfoSet, and estimates how far a way of playing is
+// from equilibrium.
+package cfr
+
+import (
+	"math/rand"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// Trainer minimises the regrets of one table: a number of players and a
+// hand size.
+type Trainer struct {
+	Players, HandSize int
+	Iterations        int // run so far
+
+	regrets map[string][]float64
+	average map[string][]float64 // the strategies played, summed
+	rng     *rand.Rand
+	// names the trump when a Wizard is turned up, which the tables leave out
+	namer ai.Agent
+	// when set, the seats but the traverser play it rather than learn
+	fixed Policy
+}
+
+// Policy takes the decision of the seat to act.
+type Policy func(state *engine.State) engine.Action
+
+func NewTrainer(players, handSize int, seed int64) *Trainer {
+	return &Trainer{
+		Players:  players,
+		HandSize: handSize,
+		regrets:  map[string][]float64{},
+		average:  map[string][]float64{},
+		rng:      rand.New(rand.NewSource(seed)),
+		namer:    ai.New(ai.Medium, ai.Balanced, seed),
+	}
+}
+
+// Run runs iterations more: every one deals a turn for each seat in turn
+// and walks the tree from it, trying every move of that seat.
+func (t *Trainer) Run(iterations int) {
+	for range iterations {
+		for traverser := range t.Players {
+			t.traverse(t.deal(), traverser)
+		}
+		t.Iterations++
+	}
+}
+
+// deal shuffles a deck and deals a turn with a random dealer.
+func (t *Trainer) deal() *engine.State {
+	turnDeck := deck.InitDeck()
+	turnDeck.ShuffleWith(t.rng)
+	return engine.NewState(t.Players, t.rng.Intn(t.Players), t.HandSize, turnDeck)
+}
+
+// traverse returns the traverser's score from the state on: its own moves
+// are all tried and their regrets updated, the other seats' drawn from the
+// current strategy, which is added to the average.
+func (t *Trainer) traverse(state *engine.State, traverser int) float64 {
+	for {
+		switch state.Phase {
+		case engine.Done:
+			return float64(engine.Score(state.Bids[traverser], state.Won[traverser]))
+		case engine.Choosing:
+			state.Apply(arena.Decide(t.namer, state))
+			continue
+		}
+		if state.ToAct != traverser && t.fixed != nil {
+			state.Apply(t.fixed(state))
+			continue
+		}
+
+		view := state.View(state.ToAct)
+		key, actions := ai.InfoSet(&view)
+		if len(actions) == 1 {
+			state.Apply(actions[0])
+			continue
+		}
+		regrets := t.regrets[key]
+		if regrets == nil {
+			regrets = make([]float64, len(actions))
+			t.regrets[key] = regrets
+		}
+		strategy := matching(regrets)
+
+		if state.ToAct != traverser {
+			average := t.average[key]
+			if average == nil {
+				average = make([]float64, len(actions))
+				t.average[key] = average
+			}
+			for i, p := range strategy {
+				average[i] += p
+			}
+			state.Apply(actions[draw(strategy, t.rng)])
+			continue
+		}
+
+		values := make([]float64, len(actions))
+		node := 0.0
+		for i, action := range actions {
+			next := state.Clone()
+			next.Apply(action)
+			values[i] = t.traverse(next, traverser)
+			node += strategy[i] * values[i]
+		}
+		for i := range regrets {
+			regrets[i] += values[i] - node
+		}
+		return node
+	}
+}
+
+// matching is regret matching: the moves in proportion to their positive
+// regret, all alike when none has any.
+func matching(regrets []float64) []float64 {
+	strategy := make([]float64, len(regrets))
+	total := 0.0
+	for i, r := range regrets {
+		strategy[i] = max(0, r)
+		total += strategy[i]
+	}
+	for i := range strategy {
+		if total > 0 {
+			strategy[i] /= total
+		} else {
+			strategy[i] = 1 / float64(len(strategy))
+		}
+	}
+	return strategy
+}
+
+func draw(probabilities []float64, rng *rand.Rand) int {
+	x := rng.Float64()
+	for i, p := range probabilities {
+		if x < p {
+			return i
+		}
+		x -= p
+	}
+	return len(probabilities) - 1
+}
+
+// Export adds the average strategy of every information set the trainer
+// visited to the strategy table.
+func (t *Trainer) Export(strategy *ai.Strategy) {
+	for key, sum := range t.average {
+		total := 0.0
+		for _, x := range sum {
+			total += x
+		}
+		probabilities := make([]float64, len(sum))
+		for i, x := range sum {
+			probabilities[i] = x / total
+		}
+		strategy.Set(key, probabilities)
+	}
+	strategy.Iterations[ai.StrategyTable(t.Players, t.HandSize)] = t.Iterations
+}
+
+// InfoSets counts the information sets the trainer has visited.
+func (t *Trainer) InfoSets() int {
+	return len(t.regrets)
+}
@@ -0,0 +1,57 @@
+package cfr_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/cfr"
+	"wizard/deck"
+	"wizard/engine"
+)
+
+// TestCFR trains the smallest table, saves it and checks the cfr agent plays
+// it legally and is less exploitable than the medium level
+func TestCFR(t *testing.T) {
+	trainer := cfr.NewTrainer(3, 1, 1)
+	trainer.Run(4000)
+	strategy := ai.NewStrategy()
+	trainer.Export(strategy)
+	if len(strategy.Tables) == 0 || strategy.Iterations[ai.StrategyTable(3, 1)] != 4000 {
+		t.Fatalf("exported %d information sets, %v", len(strategy.Tables), strategy.Iterations)
+	}
+
+	path := t.TempDir() + "/strategy.gz"
+	if err := strategy.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ai.LoadStrategy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, probabilities := range strategy.Tables {
+		if !slices.Equal(loaded.Tables[key], probabilities) {
+			t.Fatalf("%s saved as %v, loaded as %v", key, probabilities, loaded.Tables[key])
+		}
+	}
+
+	rng := rand.New(rand.NewSource(2))
+	agent := ai.NewCFRAgent(loaded, ai.Balanced, 3)
+	for range 200 {
+		turnDeck := deck.InitDeck()
+		turnDeck.ShuffleWith(rng)
+		state := engine.NewState(3, rng.Intn(3), 1, turnDeck)
+		for state.Phase != engine.Done {
+			if err := state.Apply(arena.Decide(agent, state)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	equilibrium, _ := cfr.Exploitability(3, 1, cfr.StrategyPolicy(loaded, 4), 3000, 1500, 5)
+	medium, _ := cfr.Exploitability(3, 1, cfr.AgentPolicy(ai.New(ai.Medium, ai.Balanced, 4)), 3000, 1500, 5)
+	if equilibrium >= medium {
+		t.Errorf("a best response gains %.2f against the strategy, %.2f against medium", equilibrium, medium)
+	}
+}
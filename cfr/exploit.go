@@ -0,0 +1,80 @@
+package cfr
+
+import (
+	"math"
+	"slices"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/engine"
+)
+
+// AgentPolicy has every seat decide as agent.
+func AgentPolicy(agent ai.Agent) Policy {
+	return func(state *engine.State) engine.Action {
+		return arena.Decide(agent, state)
+	}
+}
+
+// StrategyPolicy has every seat play the strategy table, as the cfr level
+// does.
+func StrategyPolicy(strategy *ai.Strategy, seed int64) Policy {
+	return AgentPolicy(ai.NewCFRAgent(strategy, ai.Balanced, seed))
+}
+
+// Exploitability estimates the points a seat gains in a turn by playing a
+// best response when every other seat follows profile, with the standard
+// error of the estimate. The best response is learned by regret minimisation
+// against the profile over iterations, then played against it on fresh
+// deals, once in each seat, and compared with the profile in the same seat
+// on the same deal. Within the abstraction and the iterations it is a lower
+// bound: a profile at equilibrium gains nothing from it.
+func Exploitability(players, handSize int, profile Policy, iterations, deals int, seed int64) (float64, float64) {
+	responder := NewTrainer(players, handSize, seed)
+	responder.fixed = profile
+	responder.Run(iterations)
+	respond := func(state *engine.State) engine.Action {
+		view := state.View(state.ToAct)
+		key, actions := ai.InfoSet(&view)
+		regrets, ok := responder.regrets[key]
+		if !ok || len(regrets) != len(actions) {
+			return profile(state)
+		}
+		return actions[slices.Index(regrets, slices.Max(regrets))]
+	}
+
+	dealer := NewTrainer(players, handSize, seed+1)
+	sum, squares := 0.0, 0.0
+	for range deals {
+		deal := dealer.deal()
+		gain := 0.0
+		for seat := range players {
+			gain += playOut(deal.Clone(), seat, respond, profile, dealer.namer) - playOut(deal.Clone(), seat, profile, profile, dealer.namer)
+		}
+		gain /= float64(players)
+		sum += gain
+		squares += gain * gain
+	}
+	mean := sum / float64(deals)
+	variance := max(0, squares/float64(deals)-mean*mean)
+	return mean, math.Sqrt(variance / float64(deals))
+}
+
+// playOut plays the turn with seat following its policy and the others
+// theirs, and returns the seat's score.
+func playOut(state *engine.State, seat int, own, others Policy, namer ai.Agent) float64 {
+	for state.Phase != engine.Done {
+		var action engine.Action
+		switch {
+		case state.Phase == engine.Choosing:
+			action = arena.Decide(namer, state)
+		case state.ToAct == seat:
+			action = own(state)
+		default:
+			action = others(state)
+		}
+		if err := state.Apply(action); err != nil {
+			state.Apply(state.LegalActions()[0])
+		}
+	}
+	return float64(engine.Score(state.Bids[seat], state.Won[seat]))
+}
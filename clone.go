@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"wizard/ai"
+	"wizard/card"
+	"wizard/engine"
+	"wizard/gamelog"
+)
+
+// runClone fits the clone of a player on their logged decisions, saves it and
+// reports how often it agrees with the player on the games kept out.
+func runClone(args []string) {
+	flags := flag.NewFlagSet("clone", flag.ExitOnError)
+	log := flags.String("log", "games.jsonl", "game log to learn from")
+	player := flags.String("player", "", "name of the human player to imitate")
+	holdout := flags.Float64("holdout", 0.2, "share of the player's games, the latest, kept out of the fit to measure the agreement on")
+	ridge := flags.Float64("ridge", 1, "penalty on the size of the weights")
+	out := flags.String("out", "", "file the clone is saved to, <player>.clone.json by default")
+	flags.Parse(args)
+
+	if *player == "" {
+		fmt.Fprintln(os.Stderr, "name the player to imitate with -player")
+		os.Exit(2)
+	}
+	if *out == "" {
+		*out = *player + ".clone.json"
+	}
+	logged, err := gamelog.Read(*log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var games []gamelog.Game
+	for _, game := range logged {
+		if humanSeat(game, *player) >= 0 {
+			games = append(games, game)
+		}
+	}
+	if len(games) == 0 {
+		fmt.Fprintf(os.Stderr, "%s played no game in %s\n", *player, *log)
+		os.Exit(1)
+	}
+
+	split := len(games) - int(float64(len(games))*max(0, min(1, *holdout)))
+	bids, plays := cloneSamples(games[:split], *player)
+	clone, err := ai.FitClone(*player, bids, plays, *ridge)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v: %s should play more games\n", err, *player)
+		os.Exit(1)
+	}
+	if err := clone.Save(*out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Fitted on %d bids and %d cards of %s in %d games, saved to %s\n", len(bids), len(plays), *player, split, *out)
+	fmt.Printf("Bids spread around the prediction: %.2f\n\n", clone.Bid.Spread)
+
+	if split == len(games) {
+		return
+	}
+	bids, plays = cloneSamples(games[split:], *player)
+	fmt.Printf("Agreement with %s on the %d games kept out (%d bids, %d cards with a choice):\n", *player, len(games)-split, len(bids), len(plays))
+	fmt.Printf("%-10s %10s %14s %10s\n", "agent", "same bid", "bid within 1", "same card")
+	reportAgreement("clone", bids, plays, func(view *engine.PlayerView) int {
+		chances := clone.BidChances(view)
+		return slices.Index(chances, slices.Max(chances))
+	}, func(view *engine.PlayerView) card.Card {
+		legal, chances := clone.PlayChances(view)
+		return legal[slices.Index(chances, slices.Max(chances))]
+	})
+	medium := ai.New(ai.Medium, ai.Balanced, 1)
+	reportAgreement("medium", bids, plays, medium.Bid, medium.Play)
+}
+
+// humanSeat returns the seat the named player took as a human, -1 when they
+// didn't play the game; a log that does not tell the agents has every seat
+// taken by a human.
+func humanSeat(game gamelog.Game, name string) int {
+	for seat, player := range game.Players {
+		if player == name && (seat >= len(game.Agents) || game.Agents[seat] == "") {
+			return seat
+		}
+	}
+	return -1
+}
+
+// cloneSamples replays the games and returns the bids of the named player,
+// and the cards they played when they had a choice.
+func cloneSamples(games []gamelog.Game, name string) ([]ai.BidSample, []ai.PlaySample) {
+	var bids []ai.BidSample
+	var plays []ai.PlaySample
+	for _, game := range games {
+		seat := humanSeat(game, name)
+		if seat < 0 {
+			continue
+		}
+		game.Replay(func(d gamelog.Decision) {
+			if d.State.ToAct != seat {
+				return
+			}
+			view := d.State.View(seat)
+			switch d.Action.Kind {
+			case engine.BidAction:
+				bids = append(bids, ai.BidSample{View: view, Bid: d.Action.Bid})
+			case engine.PlayAction:
+				if legal := view.Trick.LegalPlays(view.Hand); slices.ContainsFunc(legal, func(c card.Card) bool { return c != legal[0] }) {
+					plays = append(plays, ai.PlaySample{View: view, Card: d.Action.Card})
+				}
+			}
+		})
+	}
+	return bids, plays
+}
+
+// reportAgreement scores how often an agent takes the logged decisions.
+func reportAgreement(name string, bids []ai.BidSample, plays []ai.PlaySample, bid func(*engine.PlayerView) int, play func(*engine.PlayerView) card.Card) {
+	same, near, cards := 0, 0, 0
+	for _, s := range bids {
+		b := bid(&s.View)
+		if b == s.Bid {
+			same++
+		}
+		if b-s.Bid <= 1 && s.Bid-b <= 1 {
+			near++
+		}
+	}
+	for _, s := range plays {
+		if play(&s.View) == s.Card {
+			cards++
+		}
+	}
+	fmt.Printf("%-10s %9.1f%% %13.1f%% %9.1f%%\n", name, percent(same, len(bids)), percent(near, len(bids)), percent(cards, len(plays)))
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/card"
+	"wizard/engine"
+	"wizard/gamelog"
+)
+
+// overbidder bids one more than the medium level and always plays its
+// highest card, habits a clone should pick up
+type overbidder struct {
+	ai.Agent
+}
+
+func (o overbidder) Bid(view *engine.PlayerView) int {
+	return min(len(view.Hand), o.Agent.Bid(view)+1)
+}
+
+func (o overbidder) Play(view *engine.PlayerView) card.Card {
+	legal := view.Trick.LegalPlays(view.Hand)
+	highest := legal[0]
+	for _, c := range legal {
+		if c.IsWizard || !highest.IsWizard && (highest.IsJoker || !c.IsJoker && c.Number > highest.Number) {
+			highest = c
+		}
+	}
+	return highest
+}
+
+// TestClone fits the clone of a player with strong habits and checks it
+// agrees with them more often than the medium level on held-out games
+func TestClone(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	var games []gamelog.Game
+	for i := range 10 {
+		agents := []ai.Agent{overbidder{ai.New(ai.Medium, ai.Balanced, 1)}, ai.New(ai.Medium, ai.Balanced, 2), ai.New(ai.Medium, ai.Balanced, 3)}
+		result := arena.Play(agents, i%3, rng)
+		games = append(games, gamelog.Game{Players: []string{"Greedy", "B", "C"}, Agents: []string{"", "medium", "medium"}, Events: result.Events})
+	}
+
+	bids, plays := cloneSamples(games[:8], "Greedy")
+	clone, err := ai.FitClone("Greedy", bids, plays, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other, _ := cloneSamples(games, "B"); len(other) != 0 {
+		t.Errorf("took %d bids of an AI seat", len(other))
+	}
+
+	path := t.TempDir() + "/greedy.clone.json"
+	if err := clone.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if clone, err = ai.LoadClone(path); err != nil {
+		t.Fatal(err)
+	}
+
+	bids, plays = cloneSamples(games[8:], "Greedy")
+	medium := ai.New(ai.Medium, ai.Balanced, 1)
+	agree := func(bid func(*engine.PlayerView) int, play func(*engine.PlayerView) card.Card) (float64, float64) {
+		sameBids, sameCards := 0, 0
+		for _, s := range bids {
+			if bid(&s.View) == s.Bid {
+				sameBids++
+			}
+		}
+		for _, s := range plays {
+			if play(&s.View) == s.Card {
+				sameCards++
+			}
+		}
+		return float64(sameBids) / float64(len(bids)), float64(sameCards) / float64(len(plays))
+	}
+	cloneBids, cloneCards := agree(func(view *engine.PlayerView) int {
+		chances := clone.BidChances(view)
+		return slices.Index(chances, slices.Max(chances))
+	}, func(view *engine.PlayerView) card.Card {
+		legal, chances := clone.PlayChances(view)
+		return legal[slices.Index(chances, slices.Max(chances))]
+	})
+	mediumBids, mediumCards := agree(medium.Bid, medium.Play)
+	if cloneBids <= mediumBids || cloneCards <= mediumCards {
+		t.Errorf("the clone agrees on %.0f%% of the bids and %.0f%% of the cards, medium on %.0f%% and %.0f%%", 100*cloneBids, 100*cloneCards, 100*mediumBids, 100*mediumCards)
+	}
+
+	// The agent draws legal decisions
+	agent := ai.NewCloneAgent(clone, ai.Balanced, 1)
+	for _, s := range plays {
+		if c := agent.Play(&s.View); !s.View.Trick.CanPlay(s.View.Hand, c) || !slices.Contains(s.View.Hand, c) {
+			t.Fatalf("the clone played %s", c)
+		}
+	}
+}
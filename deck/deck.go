@@ -49,8 +49,12 @@ func (deck Deck) Show() {
 }
 
 func (deck *Deck) Shuffle() {
-	seed := rand.New(rand.NewSource(time.Now().UnixNano()))
+	deck.ShuffleWith(rand.New(rand.NewSource(time.Now().UnixNano())))
+}
 
+// ShuffleWith shuffles the deck with the given source, so that a deal can be
+// played again.
+func (deck *Deck) ShuffleWith(seed *rand.Rand) {
 	for i := len(*deck) - 1; i > 0; i-- {
 		j := seed.Intn(i + 1)
 		(*deck)[i], (*deck)[j] = (*deck)[j], (*deck)[i]
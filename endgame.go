@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"slices"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// runEndgame reports how often solving the last tricks exactly changes the
+// card an expert player picks by sampling, and what it is worth at the table.
+func runEndgame(args []string) {
+	flags := flag.NewFlagSet("endgame", flag.ExitOnError)
+	games := flags.Int("games", 20, "games played for each part of the report")
+	players := flags.Int("players", 4, "seats at the table")
+	tricks := flags.Int("tricks", ai.ExpertEndgame.Tricks, "tricks left from which the turn is solved")
+	unknown := flags.Int("unknown", ai.ExpertEndgame.Unknown, "most unseen cards to solve the turn")
+	seed := flags.Int64("seed", 1, "seed of the deals and the players")
+	flags.Parse(args)
+
+	if *players < 3 || *players > 6 {
+		fmt.Fprintln(os.Stderr, "a game is played by 3 to 6 players")
+		os.Exit(2)
+	}
+	endgame := ai.Endgame{Tricks: *tricks, Unknown: *unknown}
+	ai.ExpertEndgame = endgame
+	rng := rand.New(rand.NewSource(*seed))
+
+	// Sampling experts play, and every card they pick in the endgame is
+	// checked against the solved one
+	var watches []*endgameWatch
+	for range *games {
+		agents := make([]ai.Agent, *players)
+		for seat := range agents {
+			watch := &endgameWatch{Agent: ai.NewPIMC(ai.Balanced, ai.ExpertSearch, rng.Int63()), endgame: endgame}
+			watches = append(watches, watch)
+			agents[seat] = watch
+		}
+		arena.Play(agents, 0, rng)
+	}
+	var total endgameWatch
+	for _, w := range watches {
+		total.plays += w.plays
+		total.solved += w.solved
+		total.changed += w.changed
+		total.gained += w.gained
+	}
+	fmt.Printf("Endgame within %d tricks and %d unseen cards, %d games of %d players\n", *tricks, *unknown, *games, *players)
+	fmt.Printf("Cards played by sampling:  %d\n", total.plays)
+	fmt.Printf("Of them in the endgame:    %d (%.1f%%)\n", total.solved, percent(total.solved, total.plays))
+	fmt.Printf("Changed by solving:        %d (%.1f%% of the endgame)\n", total.changed, percent(total.changed, total.solved))
+	if total.changed > 0 {
+		fmt.Printf("Points gained per change:  %.2f on average over the deals\n", total.gained/float64(total.changed))
+	}
+
+	// Solving and sampling experts play each other, swapping seats from one
+	// game to the next
+	var points, seats [2]int
+	for game := range *games {
+		agents := make([]ai.Agent, *players)
+		for seat := range agents {
+			if (seat+game)%2 == 0 {
+				agents[seat] = ai.New(ai.Expert, ai.Balanced, rng.Int63())
+			} else {
+				agents[seat] = ai.NewPIMC(ai.Balanced, ai.ExpertSearch, rng.Int63())
+			}
+		}
+		for seat, score := range arena.Play(agents, game%*players, rng).Scores {
+			side := (seat + game) % 2
+			points[side] += score
+			seats[side]++
+		}
+	}
+	fmt.Printf("\nAt the table, average score:\n")
+	fmt.Printf("%-10s %10.1f\n", "solving", float64(points[0])/float64(seats[0]))
+	fmt.Printf("%-10s %10.1f\n", "sampling", float64(points[1])/float64(seats[1]))
+}
+
+func percent(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return 100 * float64(part) / float64(whole)
+}
+
+// endgameWatch plays as its agent and tallies the endgame cards it picks
+// that score worse than the solved one.
+type endgameWatch struct {
+	ai.Agent
+	endgame                ai.Endgame
+	plays, solved, changed int
+	gained                 float64 // points over the deals, summed over the changes
+}
+
+func (w *endgameWatch) Play(view *engine.PlayerView) card.Card {
+	c := w.Agent.Play(view)
+	w.plays++
+	cards, scores, deals := ai.SolveEndgame(view, w.endgame)
+	if deals == 0 {
+		return c
+	}
+	w.solved++
+	best := slices.Max(scores)
+	if taken := scores[slices.Index(cards, c)]; taken < best {
+		w.changed++
+		w.gained += best - taken
+	}
+	return c
+}
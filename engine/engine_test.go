@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+	"wizard/card"
+	"wizard/deck"
+)
+
+var (
+	wizard = card.Card{IsWizard: true, Number: -1}
+	joker  = card.Card{IsJoker: true, Number: -1}
+)
+
+// deal builds the state of a turn dealt the given hands, seat 0 first, with
+// trump turned up. Seat 2 deals, so seat 0 bids first and leads.
+func deal(trump card.Card, hands ...[]card.Card) *State {
+	turnDeck := deck.Deck{trump}
+	for seat := len(hands) - 1; seat >= 0; seat-- {
+		turnDeck = append(turnDeck, hands[seat]...)
+	}
+	return NewState(len(hands), len(hands)-1, len(hands[0]), turnDeck)
+}
+
+// TestApply plays the first trick of a turn and checks which moves the rules
+// refuse and who takes the trick.
+func TestApply(t *testing.T) {
+	green10 := card.Card{Number: 10, Symbol: card.Green}
+	yellow7 := card.Card{Number: 7, Symbol: card.Yellow}
+	red1 := card.Card{Number: 1, Symbol: card.Red}
+	blue13 := card.Card{Number: 13, Symbol: card.Blue}
+	yellow9 := card.Card{Number: 9, Symbol: card.Yellow}
+	green3 := card.Card{Number: 3, Symbol: card.Green}
+	blue2 := card.Card{Number: 2, Symbol: card.Blue}
+	hands := [][]card.Card{
+		{green10, joker, yellow7},
+		{red1, blue13, yellow9},
+		{wizard, green3, blue2},
+	}
+	trump := card.Card{Number: 5, Symbol: card.Red}
+
+	tests := []struct {
+		name   string
+		bids   []int
+		plays  []card.Card
+		err    bool // the last move is refused
+		winner int  // who takes the trick, when it is complete
+	}{
+		{name: "prediction above the hand size", bids: []int{4}, err: true},
+		{name: "negative prediction", bids: []int{-1}, err: true},
+		{name: "card before the predictions", bids: []int{1}, plays: []card.Card{green10}, err: true},
+		{name: "card not in the hand", bids: []int{1, 1, 1}, plays: []card.Card{blue2}, err: true},
+		{name: "suit not followed", bids: []int{1, 1, 1}, plays: []card.Card{green10, red1, blue2}, err: true},
+		{name: "trump beats the suit", bids: []int{1, 1, 1}, plays: []card.Card{green10, red1, green3}, winner: 1},
+		{name: "suit beats a higher card off suit", bids: []int{1, 1, 1}, plays: []card.Card{green10, blue13, green3}, winner: 0},
+		{name: "Wizard beats the trump", bids: []int{1, 1, 1}, plays: []card.Card{green10, red1, wizard}, winner: 2},
+		{name: "Joker lead leaves the suit to the next card", bids: []int{1, 1, 1}, plays: []card.Card{joker, blue13, blue2}, winner: 1},
+		{name: "Wizard lead wins whatever follows", bids: []int{1, 1, 1}, plays: []card.Card{yellow7, yellow9, wizard}, winner: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := deal(trump, hands...)
+			var err error
+			for _, bid := range test.bids {
+				if err = state.Apply(BidOf(bid)); err != nil {
+					break
+				}
+			}
+			for _, c := range test.plays {
+				if err != nil {
+					break
+				}
+				err = state.Apply(PlayOf(c))
+			}
+			if (err != nil) != test.err {
+				t.Fatalf("the last move returned %v", err)
+			}
+			if test.err {
+				return
+			}
+
+			if len(state.History) != 1 || state.Won[test.winner] != 1 || state.ToAct != test.winner {
+				t.Errorf("tricks %v and seat %d to act, want seat %d taking the trick", state.Won, state.ToAct, test.winner)
+			}
+			for seat, hand := range state.Hands {
+				if len(hand) != 2 || slices.Contains(hand, state.History[0].Cards[slices.Index(state.History[0].Seats, seat)]) {
+					t.Errorf("seat %d still holds %v", seat, hand)
+				}
+			}
+		})
+	}
+}
+
+// TestTurnScores plays a turn of one card to the end and scores it.
+func TestTurnScores(t *testing.T) {
+	state := deal(card.Card{Number: 5, Symbol: card.Red},
+		[]card.Card{{Number: 12, Symbol: card.Green}},
+		[]card.Card{{Number: 2, Symbol: card.Red}},
+		[]card.Card{joker})
+	for _, action := range []Action{BidOf(1), BidOf(1), BidOf(0)} {
+		if err := state.Apply(action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state.Phase != Playing || state.ToAct != 0 {
+		t.Fatalf("phase %d, seat %d to act after the predictions", state.Phase, state.ToAct)
+	}
+	for _, hand := range slices.Clone(state.Hands) {
+		if err := state.Apply(PlayOf(hand[0])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state.Phase != Done {
+		t.Fatalf("phase %d after the last trick", state.Phase)
+	}
+	if scores := state.Scores(); !slices.Equal(scores, []int{-10, 30, 20}) {
+		t.Errorf("scores %v", scores)
+	}
+	if err := state.Apply(PlayOf(joker)); err == nil {
+		t.Error("a card was played once the turn was over")
+	}
+}
@@ -0,0 +1,67 @@
+package engine
+
+import "wizard/card"
+
+type EventKind int
+
+const (
+	DealEvent  EventKind = iota // Seat was dealt Hand; only told to that seat
+	TrumpEvent                  // Card was turned up, Suit is trump ("" for none); told again once the dealer names it
+	BidEvent                    // Seat predicted Bid tricks
+	PlayEvent                   // Seat played Card
+	TrickEvent                  // Seat took the trick with Card
+	ScoreEvent                  // Seat took Tricks for its Bid and made Points
+)
+
+// Event is something that happened during a turn. Events are kept in the
+// game log as JSON.
+type Event struct {
+	Kind     EventKind   `json:"kind"`
+	Seat     int         `json:"seat"`
+	Players  int         `json:"players"` // number of seats at the table
+	Dealer   int         `json:"dealer"`
+	HandSize int         `json:"handSize"`
+	Hand     []card.Card `json:"hand,omitempty"`
+	Card     card.Card   `json:"card,omitzero"`
+	Suit     card.Symbol `json:"suit,omitempty"`
+	Bid      int         `json:"bid,omitempty"`
+	Tricks   int         `json:"tricks,omitempty"`
+	Points   int         `json:"points,omitempty"`
+}
+
+// Everyone observes with every seat's deal, as an event log does.
+const Everyone = -1
+
+type observer struct {
+	seat int
+	fn   func(Event)
+}
+
+// Observe registers fn to be told the events of the turn from seat's side of
+// the table: the deal of the other seats is kept from it. The deal and the
+// trump are told right away, so Observe is called before the first bid.
+func (s *State) Observe(seat int, fn func(Event)) {
+	o := observer{seat: seat, fn: fn}
+	s.observers = append(s.observers, o)
+
+	for deal := range s.NumPlayers() {
+		if seat == Everyone || seat == deal {
+			o.fn(s.event(Event{Kind: DealEvent, Seat: deal, Hand: s.dealt[deal]}))
+		}
+	}
+	o.fn(s.event(Event{Kind: TrumpEvent, Seat: s.Dealer, Card: s.Trump, Suit: s.TrumpSuit}))
+}
+
+func (s *State) emit(e Event) {
+	e = s.event(e)
+	for _, o := range s.observers {
+		o.fn(e)
+	}
+}
+
+func (s *State) event(e Event) Event {
+	e.Players = s.NumPlayers()
+	e.Dealer = s.Dealer
+	e.HandSize = s.HandSize
+	return e
+}
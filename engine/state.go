@@ -0,0 +1,281 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"wizard/card"
+	"wizard/deck"
+)
+
+type Phase int
+
+const (
+	Bidding Phase = iota
+	Playing
+	Done
+	Choosing // a Wizard was turned up: the dealer names the trump
+)
+
+type ActionKind int
+
+const (
+	BidAction ActionKind = iota
+	PlayAction
+	TrumpAction
+)
+
+// Action is a single decision of the seat to act: a bid, a card or the trump.
+type Action struct {
+	Kind ActionKind
+	Bid  int
+	Card card.Card
+	Suit card.Symbol
+}
+
+func BidOf(bid int) Action { return Action{Kind: BidAction, Bid: bid} }
+
+func PlayOf(c card.Card) Action { return Action{Kind: PlayAction, Card: c} }
+
+func TrumpOf(suit card.Symbol) Action { return Action{Kind: TrumpAction, Suit: suit} }
+
+// State is a whole turn played without any console I/O: the deal, the
+// predictions and every trick. The console game and the AI simulations
+// both run on it.
+type State struct {
+	Dealer    int
+	HandSize  int
+	Hands     [][]card.Card
+	Trump     card.Card
+	TrumpSuit card.Symbol
+	Bids      []int
+	Won       []int
+	Trick     Trick
+	History   []Trick // the completed tricks
+	Phase     Phase
+	ToAct     int // the seat whose decision is awaited
+
+	dealt     [][]card.Card // the hands as dealt, for the observers
+	observers []observer
+}
+
+// NewState deals handSize cards to every seat from a shuffled deck and turns
+// up the trump. When the deck runs out, or a Joker is turned up, there is no
+// trump; when a Wizard is, the dealer names it before the bidding starts.
+func NewState(numPlayers, dealer, handSize int, turnDeck deck.Deck) *State {
+	s := &State{
+		Dealer:   dealer,
+		HandSize: handSize,
+		Hands:    make([][]card.Card, numPlayers),
+		Bids:     make([]int, numPlayers),
+		Won:      make([]int, numPlayers),
+	}
+
+	s.dealt = make([][]card.Card, numPlayers)
+	for seat := range numPlayers {
+		s.dealt[seat] = slices.Clone(turnDeck.Draw(handSize))
+		s.Hands[seat] = slices.Clone(s.dealt[seat])
+		s.Bids[seat] = -1
+	}
+	if len(turnDeck) > 0 {
+		s.Trump = turnDeck.Draw(1)[0]
+		s.TrumpSuit = s.Trump.Symbol
+	}
+
+	s.ToAct = (dealer + 1) % numPlayers
+	if s.Trump.IsWizard {
+		s.Phase = Choosing
+		s.ToAct = dealer
+	}
+	s.Trick = NewTrick(s.TrumpSuit)
+	return s
+}
+
+func (s *State) NumPlayers() int {
+	return len(s.Hands)
+}
+
+// Clone returns a deep copy of the state that can be played on independently.
+// The copy has no observers.
+func (s *State) Clone() *State {
+	c := *s
+	c.observers = nil
+	c.Hands = make([][]card.Card, len(s.Hands))
+	for i, hand := range s.Hands {
+		c.Hands[i] = slices.Clone(hand)
+	}
+	c.Bids = slices.Clone(s.Bids)
+	c.Won = slices.Clone(s.Won)
+	c.Trick = s.Trick.Clone()
+	c.History = slices.Clone(s.History)
+	return &c
+}
+
+// LegalActions returns the decisions open to the seat to act.
+func (s *State) LegalActions() []Action {
+	switch s.Phase {
+	case Choosing:
+		actions := make([]Action, 0, len(card.Symbols))
+		for _, suit := range card.Symbols {
+			actions = append(actions, TrumpOf(suit))
+		}
+		return actions
+	case Bidding:
+		actions := make([]Action, 0, s.HandSize+1)
+		for bid := 0; bid <= s.HandSize; bid++ {
+			actions = append(actions, BidOf(bid))
+		}
+		return actions
+	case Playing:
+		legal := s.Trick.LegalPlays(s.Hands[s.ToAct])
+		actions := make([]Action, 0, len(legal))
+		for _, c := range legal {
+			if !slices.ContainsFunc(actions, func(a Action) bool { return a.Card == c }) {
+				actions = append(actions, PlayOf(c))
+			}
+		}
+		return actions
+	}
+	return nil
+}
+
+// Apply carries out the decision of the seat to act.
+func (s *State) Apply(action Action) error {
+	switch {
+	case s.Phase == Bidding && action.Kind == BidAction:
+		return s.bid(action.Bid)
+	case s.Phase == Playing && action.Kind == PlayAction:
+		return s.play(action.Card)
+	case s.Phase == Choosing && action.Kind == TrumpAction:
+		return s.chooseTrump(action.Suit)
+	}
+	return fmt.Errorf("action not allowed in this phase of the turn")
+}
+
+func (s *State) chooseTrump(suit card.Symbol) error {
+	if !slices.Contains(card.Symbols, suit) {
+		return fmt.Errorf("'%s' is not a symbol", suit)
+	}
+	s.TrumpSuit = suit
+	s.Trick = NewTrick(suit)
+	s.emit(Event{Kind: TrumpEvent, Seat: s.Dealer, Card: s.Trump, Suit: suit})
+
+	s.Phase = Bidding
+	s.ToAct = (s.Dealer + 1) % s.NumPlayers()
+	return nil
+}
+
+func (s *State) bid(bid int) error {
+	if bid < 0 || bid > s.HandSize {
+		return fmt.Errorf("a prediction must be between 0 and %d", s.HandSize)
+	}
+	s.Bids[s.ToAct] = bid
+	s.emit(Event{Kind: BidEvent, Seat: s.ToAct, Bid: bid})
+	s.ToAct = (s.ToAct + 1) % s.NumPlayers()
+
+	// The player after the dealer bids first and leads the first round
+	if s.ToAct == (s.Dealer+1)%s.NumPlayers() {
+		s.Phase = Playing
+	}
+	return nil
+}
+
+func (s *State) play(c card.Card) error {
+	hand := s.Hands[s.ToAct]
+	index := slices.Index(hand, c)
+	if index < 0 {
+		return fmt.Errorf("the card is not in the hand")
+	}
+	if !s.Trick.CanPlay(hand, c) {
+		return fmt.Errorf("the suit (%s) must be followed", s.Trick.Suit)
+	}
+
+	s.Hands[s.ToAct] = slices.Delete(hand, index, index+1)
+	s.Trick.Play(s.ToAct, c)
+	s.emit(Event{Kind: PlayEvent, Seat: s.ToAct, Card: c})
+	s.ToAct = (s.ToAct + 1) % s.NumPlayers()
+
+	if len(s.Trick.Cards) == s.NumPlayers() {
+		// The tricker leads the next round
+		winner := s.Trick.Winner()
+		s.Won[winner]++
+		s.History = append(s.History, s.Trick)
+		s.Trick = NewTrick(s.TrumpSuit)
+		s.ToAct = winner
+		s.emit(Event{Kind: TrickEvent, Seat: winner, Card: *s.History[len(s.History)-1].Highest()})
+
+		if len(s.History) == s.HandSize {
+			s.Phase = Done
+			for seat, points := range s.Scores() {
+				s.emit(Event{Kind: ScoreEvent, Seat: seat, Bid: s.Bids[seat], Tricks: s.Won[seat], Points: points})
+			}
+		}
+	}
+	return nil
+}
+
+// View returns what seat knows of the state. The slices are shared with the
+// state and must not be modified.
+func (s *State) View(seat int) PlayerView {
+	return PlayerView{
+		Seat:      seat,
+		Dealer:    s.Dealer,
+		HandSize:  s.HandSize,
+		Hand:      s.Hands[seat],
+		Trump:     s.Trump,
+		TrumpSuit: s.TrumpSuit,
+		Bids:      s.Bids,
+		Won:       s.Won,
+		Trick:     s.Trick,
+		History:   s.History,
+	}
+}
+
+// Scores returns the points every seat makes on the turn so far.
+func (s *State) Scores() []int {
+	scores := make([]int, s.NumPlayers())
+	for seat := range scores {
+		scores[seat] = Score(s.Bids[seat], s.Won[seat])
+	}
+	return scores
+}
+
+// Score: an exact prediction is worth 20 points plus 10 per trick, every
+// trick off the prediction costs 10 points.
+func Score(predicted, won int) int {
+	if predicted == won {
+		return 20 + 10*won
+	}
+	if predicted > won {
+		return -10 * (predicted - won)
+	}
+	return -10 * (won - predicted)
+}
+
+// StateFromView rebuilds a state from what seat knows, with the given hands
+// for every seat, at the point where seat has to decide. The AI uses it to
+// play out guesses of the opponents' hands.
+func StateFromView(view *PlayerView, hands [][]card.Card) *State {
+	s := &State{
+		Dealer:    view.Dealer,
+		HandSize:  view.HandSize,
+		Hands:     make([][]card.Card, len(hands)),
+		Trump:     view.Trump,
+		TrumpSuit: view.TrumpSuit,
+		Bids:      slices.Clone(view.Bids),
+		Won:       slices.Clone(view.Won),
+		Trick:     view.Trick.Clone(),
+		History:   slices.Clone(view.History),
+		Phase:     Playing,
+		ToAct:     view.Seat,
+	}
+	for seat, hand := range hands {
+		s.Hands[seat] = slices.Clone(hand)
+	}
+	if slices.Contains(s.Bids, -1) {
+		s.Phase = Bidding
+	}
+	if s.Trump.IsWizard && s.TrumpSuit == "" {
+		s.Phase = Choosing
+	}
+	return s
+}
@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"testing"
+	"wizard/card"
+	"wizard/deck"
+)
+
+// TestWizardTurnedUp checks that a Wizard turned up lets the dealer name the
+// trump
+func TestWizardTurnedUp(t *testing.T) {
+	hand := []card.Card{
+		{Number: 13, Symbol: card.Green},
+		{Number: 11, Symbol: card.Green},
+		{Number: 8, Symbol: card.Green},
+		{Number: 2, Symbol: card.Red},
+	}
+	turnDeck := deck.Deck{{IsWizard: true, Number: -1}, hand[0], hand[1], hand[2], hand[3]}
+	state := NewState(4, 2, 1, turnDeck)
+	if state.Phase != Choosing || state.ToAct != 2 {
+		t.Fatalf("phase %d, seat %d to act after a Wizard was turned up", state.Phase, state.ToAct)
+	}
+	if err := state.Apply(TrumpOf(card.Red)); err != nil {
+		t.Fatal(err)
+	}
+	if state.TrumpSuit != card.Red || state.Phase != Bidding || state.ToAct != 3 {
+		t.Errorf("trump %s, phase %d, seat %d to act after naming the trump", state.TrumpSuit, state.Phase, state.ToAct)
+	}
+}
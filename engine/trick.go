@@ -0,0 +1,163 @@
+// Package engine holds the rules of Wizard without any console I/O, so that
+// the console game and the AI seats agree on how a trick is played and won.
+package engine
+
+import (
+	"slices"
+	"wizard/card"
+)
+
+// Trick is a single round of play: one card from every seat, starting with
+// the seat that leads.
+type Trick struct {
+	Trump card.Symbol // "" when no trump is in force
+	Suit  card.Symbol // the symbol to follow, "" until a numbered card is led
+	Cards []card.Card
+	Seats []int // Seats[i] played Cards[i]
+
+	best    int  // index in Cards of the card currently taking the trick
+	decided bool // the suit has been set (or a Wizard led and there is none)
+}
+
+func NewTrick(trump card.Symbol) Trick {
+	return Trick{Trump: trump, best: -1}
+}
+
+// IsHigherCard compares two numbered cards and returns true if newCard beats currentHighest
+/*
+  1. Wizards always win (highest priority)
+  2. Trump cards beat suit cards and
+  off-suit cards
+  3. Suit cards beat off-suit cards
+  4. Higher numbers win within the same
+  category
+  5. Jokers never win
+*/
+func IsHigherCard(newCard, currentHighest *card.Card, trumpSymbol, suitSymbol *card.Symbol) bool {
+	// Trump cards beat suit cards and off-suit cards
+	newIsTrump := newCard.Symbol == *trumpSymbol
+	currentIsTrump := currentHighest.Symbol == *trumpSymbol
+
+	//2. Trump cards beat suit cards and off-suit cards
+	if newIsTrump && !currentIsTrump {
+		return true
+	}
+	if !newIsTrump && currentIsTrump {
+		return false
+	}
+
+	// If both are trump or both are not trump, compare by suit and number
+	newIsSuit := newCard.Symbol == *suitSymbol
+	currentIsSuit := currentHighest.Symbol == *suitSymbol
+
+	// Suit cards beat off-suit cards
+	if newIsSuit && !currentIsSuit {
+		return true
+	}
+	if !newIsSuit && currentIsSuit {
+		return false
+	}
+
+	// If same type (both trump, both suit, or both off-suit), higher number wins
+	return newCard.Number > currentHighest.Number
+}
+
+// Beats reports whether c would take the trick if it were played now.
+func (t *Trick) Beats(c card.Card) bool {
+	if len(t.Cards) == 0 {
+		return true
+	}
+	highest := t.Cards[t.best]
+
+	switch {
+	case highest.IsWizard:
+		// The first Wizard always wins
+		return false
+	case c.IsWizard:
+		return true
+	case c.IsJoker:
+		// Joker never wins, unless every card is a Joker: then the first one does
+		return false
+	case highest.IsJoker:
+		// Any non-joker beats a joker
+		return true
+	}
+
+	return IsHigherCard(&c, &highest, &t.Trump, &t.Suit)
+}
+
+// Play adds the card played by seat to the trick.
+func (t *Trick) Play(seat int, c card.Card) {
+	if t.Beats(c) {
+		t.best = len(t.Cards)
+	}
+	t.Cards = append(t.Cards, c)
+	t.Seats = append(t.Seats, seat)
+
+	// A Joker lead leaves the suit to the next card; a Wizard lead means
+	// there is no suit to follow at all.
+	if !t.decided && !c.IsJoker {
+		t.decided = true
+		if !c.IsWizard {
+			t.Suit = c.Symbol
+		}
+	}
+}
+
+// Highest returns the card currently taking the trick, nil on an empty trick.
+func (t *Trick) Highest() *card.Card {
+	if len(t.Cards) == 0 {
+		return nil
+	}
+	return &t.Cards[t.best]
+}
+
+// Winner returns the seat currently taking the trick, -1 on an empty trick.
+func (t *Trick) Winner() int {
+	if len(t.Cards) == 0 {
+		return -1
+	}
+	return t.Seats[t.best]
+}
+
+// CanPlay reports whether c may be played from hand: a numbered card of the
+// suit must be followed when the hand holds one, Wizards and Jokers always may.
+func (t *Trick) CanPlay(hand []card.Card, c card.Card) bool {
+	if t.Suit == "" || c.IsWizard || c.IsJoker || c.Symbol == t.Suit {
+		return true
+	}
+	for _, h := range hand {
+		if !h.IsWizard && !h.IsJoker && h.Symbol == t.Suit {
+			return false
+		}
+	}
+	return true
+}
+
+// ProvesVoid returns the symbol a seat shows it doesn't hold by legally
+// playing c: a numbered card that doesn't follow the suit. Wizards and
+// Jokers may always be played, so they prove nothing.
+func (t *Trick) ProvesVoid(c card.Card) (card.Symbol, bool) {
+	if t.Suit == "" || c.IsWizard || c.IsJoker || c.Symbol == t.Suit {
+		return "", false
+	}
+	return t.Suit, true
+}
+
+// LegalPlays returns the cards of hand that may be played to the trick.
+func (t *Trick) LegalPlays(hand []card.Card) []card.Card {
+	legal := make([]card.Card, 0, len(hand))
+	for _, c := range hand {
+		if t.CanPlay(hand, c) {
+			legal = append(legal, c)
+		}
+	}
+	return legal
+}
+
+// Clone returns a copy of the trick that can be played on independently.
+func (t Trick) Clone() Trick {
+	t.Cards = slices.Clone(t.Cards)
+	t.Seats = slices.Clone(t.Seats)
+	return t
+}
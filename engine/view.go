@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"slices"
+	"wizard/card"
+	"wizard/deck"
+)
+
+// PlayerView is what a seat legitimately knows when it has to bid or play:
+// its own hand, the revealed trump and everything played in public.
+type PlayerView struct {
+	Seat      int
+	Dealer    int
+	HandSize  int // cards dealt to every seat this turn
+	Hand      []card.Card
+	Trump     card.Card   // the revealed trump card, zero when none was turned up
+	TrumpSuit card.Symbol // the trump in force, "" for no trump
+	Bids      []int       // per seat, -1 while the seat hasn't bid yet
+	Won       []int       // tricks taken so far this turn, per seat
+	Trick     Trick       // the trick being played
+	History   []Trick     // the tricks completed this turn
+}
+
+func (v *PlayerView) NumPlayers() int {
+	return len(v.Bids)
+}
+
+// TricksLeft counts the tricks still to be played, the current one included.
+func (v *PlayerView) TricksLeft() int {
+	return len(v.Hand)
+}
+
+// Need returns how many more tricks seat has to take to make its bid;
+// negative once it has taken too many.
+func (v *PlayerView) Need(seat int) int {
+	return v.Bids[seat] - v.Won[seat]
+}
+
+// StillToPlay returns the seats that will play to the current trick after this one.
+func (v *PlayerView) StillToPlay() []int {
+	n := v.NumPlayers()
+	seats := make([]int, 0, n)
+	for i := len(v.Trick.Cards) + 1; i < n; i++ {
+		seats = append(seats, (v.Seat+i-len(v.Trick.Cards))%n)
+	}
+	return seats
+}
+
+// CardsLeft returns how many cards seat still holds.
+func (v *PlayerView) CardsLeft(seat int) int {
+	left := v.HandSize - len(v.History)
+	if slices.Contains(v.Trick.Seats, seat) {
+		left--
+	}
+	return left
+}
+
+// Unseen returns the cards the seat hasn't seen: neither in its hand, nor
+// played, nor turned up as trump. They are in the opponents' hands or still
+// in the deck.
+func (v *PlayerView) Unseen() []card.Card {
+	seen := make([]card.Card, 0, 60)
+	seen = append(seen, v.Hand...)
+	seen = append(seen, v.Trick.Cards...)
+	for _, trick := range v.History {
+		seen = append(seen, trick.Cards...)
+	}
+	if v.Trump != (card.Card{}) {
+		seen = append(seen, v.Trump)
+	}
+
+	unseen := deck.InitDeck()
+	for _, c := range seen {
+		if i := slices.Index(unseen, c); i >= 0 {
+			unseen = slices.Delete(unseen, i, i+1)
+		}
+	}
+	return unseen
+}
@@ -3,34 +3,213 @@ package game
 import (
 	"fmt"
 	"math"
+	"math/rand"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/engine"
+	"wizard/gamelog"
 	"wizard/player"
+	"wizard/protocol"
+	"wizard/rating"
 )
 
 type Game struct {
 	Players player.Players
+	// Opponents, when set, is read by the AI seats and learns from the game
+	Opponents *ai.OpponentBook
+	// Ratings, when set, rates the human players and the AI configurations
+	// on the final scores once the game is over
+	Ratings *rating.Book
+	// Log, when set, is the game log the game is added to once it is over,
+	// with the reasons of the AI seats' decisions
+	Log string
+	// Explain shows why the AI seats take their decisions
+	Explain bool
+	// Tutor advises the human players on their decisions
+	Tutor        bool
+	agents       map[*player.Player]ai.Agent
+	bots         map[*player.Player]string // the name of the bot playing a seat
+	rng          *rand.Rand
+	events       []engine.Event
+	explanations []gamelog.Explanation
 }
 
 func InitGame(players player.Players) Game {
-	game := Game{Players: players}
+	return InitSeededGame(players, rand.Int63())
+}
+
+// InitSeededGame sets a game up as InitGame does, the AI seats and the deals
+// of a simulated game drawing from seed, so that the same seed plays the
+// same game.
+func InitSeededGame(players player.Players, seed int64) Game {
+	game := Game{Players: players, agents: make(map[*player.Player]ai.Agent), bots: make(map[*player.Player]string), rng: rand.New(rand.NewSource(seed))}
+
+	// Every AI seat gets the agent of its difficulty level, playing in its profile
+	for _, p := range players {
+		if p.IsAI {
+			level, _ := ai.LevelByName(p.Level)
+			game.agents[p] = ai.New(level, ai.PersonalityFor(p.Profile), game.rng.Int63())
+		}
+	}
 
 	return game
 }
 
+// Assign has agent take the decisions of an AI seat instead of the agent of
+// its difficulty level. A seat assigned a bot is rated and logged as the bot.
+func (game *Game) Assign(p *player.Player, agent ai.Agent) {
+	game.agents[p] = agent
+	delete(game.bots, p)
+	if bot, ok := agent.(*protocol.ExternalAgent); ok {
+		game.bots[p] = "bot:" + bot.Name
+	}
+}
+
+// Read gives the AI seats what the book knows of the players at the table,
+// and has the book learn from the game once it is over.
+func (game *Game) Read(book *ai.OpponentBook) {
+	game.Opponents = book
+
+	profiles := game.profiles()
+	for _, agent := range game.agents {
+		if reader, ok := agent.(ai.Reader); ok {
+			reader.Read(profiles)
+		}
+	}
+}
+
+// profiles returns what the book knows of the player of every seat.
+func (game *Game) profiles() []*ai.OpponentProfile {
+	profiles := make([]*ai.OpponentProfile, len(game.Players))
+	for seat, p := range game.Players {
+		profiles[seat] = game.Opponents.Profile(p.Name)
+	}
+	return profiles
+}
+
+// tellStandings gives the AI seats playing for the win the scores before the
+// turn and the turns left after it.
+func (game *Game) tellStandings(turnsLeft int) {
+	standings := ai.Standings{Scores: make([]int, len(game.Players)), TurnsLeft: turnsLeft}
+	for seat, p := range game.Players {
+		standings.Scores[seat] = p.Score
+	}
+	for _, agent := range game.agents {
+		if contender, ok := agent.(ai.Contender); ok {
+			contender.Standings(standings)
+		}
+	}
+}
+
 func (game *Game) Run(dealerPos int16) {
 
 	numberOfTurns := int(math.Floor(float64(60) / float64(len(game.Players))))
 
 	fmt.Printf("There will be %d turns.\n\n", numberOfTurns)
 	// Step 0 - init Turn
-	turn := Turn{}
+	turn := Turn{agents: game.agents, log: func(e engine.Event) { game.events = append(game.events, e) }, explain: game.Explain}
+	if game.Tutor {
+		turn.tutor = ai.NewTutor()
+		if game.Opponents != nil {
+			turn.tutor.Read(game.profiles())
+		}
+	}
+	if game.Explain || game.Log != "" {
+		for _, agent := range game.agents {
+			if explainer, ok := agent.(ai.Explainer); ok {
+				explainer.Explain()
+			}
+		}
+		// The decision is the next event
+		turn.note = func(r *ai.Rationale) {
+			game.explanations = append(game.explanations, gamelog.Explanation{Event: len(game.events), Rationale: *r})
+		}
+	}
 
 	for i := 0; i < numberOfTurns; i++ {
 		// delear goes around
 
 		currDelaerPos := ((int(dealerPos) + i) % len(game.Players))
-		fmt.Printf("\n\nCurrent Dealer: %s\n\n", game.Players[currDelaerPos].Name)
+		fmt.Printf("\n\nCurrent Dealer: %s\n\n", game.Players[currDelaerPos].Label())
 
+		game.tellStandings(numberOfTurns - i - 1)
 		turn.Run(game.Players, i+1, currDelaerPos)
 	}
 
+	if err := game.finish(); err != nil {
+		fmt.Printf("The game could not be logged: %v\n", err)
+	}
+}
+
+// Simulate plays the whole game between the AI seats without any console I/O,
+// the first turn dealt by dealerPos, and returns what came of it for every
+// seat. The players' scores are set, the opponent book learns from the game
+// and it is logged as Run does, without the reasons of the decisions. It
+// fails when a seat has no agent to play it.
+func (game *Game) Simulate(dealerPos int16) (arena.Result, error) {
+	agents := make([]ai.Agent, len(game.Players))
+	for seat, p := range game.Players {
+		agent, ok := game.agents[p]
+		if !ok {
+			return arena.Result{}, fmt.Errorf("%s is a human player: only AI seats are simulated", p.Name)
+		}
+		agents[seat] = agent
+	}
+
+	result := arena.Play(agents, int(dealerPos), rand.New(rand.NewSource(game.rng.Int63())))
+	for seat, p := range game.Players {
+		p.Score = result.Scores[seat]
+	}
+	game.events = result.Events
+	return result, game.finish()
+}
+
+// finish has the opponent book learn from the game, rates it and adds it to
+// the log.
+func (game *Game) finish() error {
+	if game.Opponents != nil {
+		names := make([]string, len(game.Players))
+		for seat, p := range game.Players {
+			names[seat] = p.Name
+		}
+		game.Opponents.Record(names, game.events)
+	}
+
+	if game.Ratings != nil {
+		seats := make([]rating.Seat, len(game.Players))
+		scores := make([]int, len(game.Players))
+		for i, p := range game.Players {
+			seats[i] = game.RatingSeat(p)
+			scores[i] = p.Score
+		}
+		game.Ratings.Record(seats, scores)
+	}
+
+	if game.Log != "" {
+		record := gamelog.Game{Events: game.events, Explanations: game.explanations}
+		for _, p := range game.Players {
+			record.Players = append(record.Players, p.Name)
+			agent := p.Level
+			if bot, ok := game.bots[p]; ok {
+				agent = bot
+			}
+			record.Agents = append(record.Agents, agent)
+		}
+		return gamelog.Append(game.Log, record)
+	}
+	return nil
+}
+
+// RatingSeat is who a player is rated as: a human by name, an AI seat by its
+// configuration, as in "hard/aggressive", and a seat played by a bot by the
+// bot's name, as in "bot:Echo".
+func (game *Game) RatingSeat(p *player.Player) rating.Seat {
+	if !p.IsAI {
+		return rating.Seat{Name: p.Name, Kind: rating.Player}
+	}
+	if bot, ok := game.bots[p]; ok {
+		return rating.Seat{Name: bot, Kind: rating.Agent}
+	}
+	level, _ := ai.LevelByName(p.Level)
+	return rating.Seat{Name: string(level) + "/" + ai.PersonalityFor(p.Profile).Name, Kind: rating.Agent}
 }
@@ -0,0 +1,116 @@
+package game_test
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+	"wizard/ai"
+	"wizard/card"
+	"wizard/engine"
+	"wizard/game"
+	"wizard/gamelog"
+	"wizard/player"
+	"wizard/protocol"
+	"wizard/rating"
+)
+
+// TestSimulate plays a game headless, rating the AI seats by their
+// configuration, and refuses a table with a human player
+func TestSimulate(t *testing.T) {
+	players := player.Register([]string{}, 3, "balanced", "aggressive")
+	players[2].Level = "easy"
+	rated := game.InitGame(players)
+	rated.Ratings = rating.NewBook()
+	result, err := rated.Simulate(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seat, p := range players {
+		if p.Score != result.Scores[seat] {
+			t.Errorf("%s scored %d, the game %d", p.Name, p.Score, result.Scores[seat])
+		}
+	}
+	for _, name := range []string{"medium/balanced", "medium/aggressive", "easy/balanced"} {
+		if e := rated.Ratings.Entry(rating.Seat{Name: name, Kind: rating.Agent}); e == nil || e.Games != 1 {
+			t.Errorf("%s is rated %+v", name, e)
+		}
+	}
+
+	humanGame := game.InitGame(player.Register([]string{"Dario"}, 2))
+	if _, err := humanGame.Simulate(0); err == nil {
+		t.Error("a game with a human player was simulated")
+	}
+}
+
+// TestSimulateBot rates and logs a seat played by a bot as the bot, not as
+// the configuration it falls back on
+func TestSimulateBot(t *testing.T) {
+	echo := t.TempDir() + "/echobot"
+	if out, err := exec.Command("go", "build", "-o", echo, "../bots/echo").CombinedOutput(); err != nil {
+		t.Fatalf("building the echo bot: %v\n%s", err, out)
+	}
+	players := player.Register([]string{}, 3, "balanced")
+	bot, err := protocol.Start([]string{echo}, nil, time.Second, ai.New(ai.Medium, ai.Balanced, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bot.Close()
+
+	table := game.InitGame(players)
+	table.Assign(players[0], bot)
+	table.Ratings = rating.NewBook()
+	table.Log = t.TempDir() + "/games.log"
+	if _, err := table.Simulate(0); err != nil {
+		t.Fatal(err)
+	}
+	if e := table.Ratings.Entry(rating.Seat{Name: "bot:Echo", Kind: rating.Agent}); e == nil || e.Games != 1 {
+		t.Errorf("the bot is rated %+v", e)
+	}
+	if e := table.Ratings.Entry(rating.Seat{Name: "medium/balanced", Kind: rating.Agent}); e == nil || e.Games != 1 {
+		t.Errorf("the built-in seats are rated %+v", e)
+	}
+	games, err := gamelog.Read(table.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 || games[0].Agents[0] != "bot:Echo" || games[0].Agents[1] != "medium" {
+		t.Errorf("the game was logged with the agents %v", games[0].Agents)
+	}
+}
+
+// cheat answers with decisions the rules don't allow
+type cheat struct{}
+
+func (cheat) Bid(view *engine.PlayerView) int { return view.HandSize + 1 }
+
+func (cheat) Play(view *engine.PlayerView) card.Card {
+	return card.Card{Number: 99, Symbol: card.Green}
+}
+
+func (cheat) Trump(view *engine.PlayerView) card.Symbol { return "" }
+
+// TestRunFallsBack plays a game in the console with a seat whose agent only
+// answers with decisions the rules don't allow: the seat plays on its
+// built-in heuristic instead.
+func TestRunFallsBack(t *testing.T) {
+	players := player.Register([]string{}, 6, "balanced")
+	table := game.InitGame(players)
+	table.Assign(players[0], cheat{})
+
+	stdout := os.Stdout
+	os.Stdout, _ = os.Open(os.DevNull)
+	defer func() { os.Stdout = stdout }()
+	table.Run(0)
+
+	total := 0
+	for _, p := range players {
+		if len(p.Hand) != 0 {
+			t.Errorf("%s still holds %v", p.Name, p.Hand)
+		}
+		total += p.Score
+	}
+	if total == 0 {
+		t.Error("no seat scored")
+	}
+}
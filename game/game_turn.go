@@ -9,9 +9,13 @@ package game
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+	"wizard/ai"
+	"wizard/arena"
 	"wizard/card"
 	"wizard/deck"
-	"wizard/game/internals"
+	"wizard/engine"
 	"wizard/player"
 )
 
@@ -25,45 +29,12 @@ type Turn struct {
 	rounds      []Round
 	predictions []Prediction
 	trump       card.Card
-}
-
-// isHigherCard compares two cards and returns true if newCard beats currentHighest
-/*
-  1. Wizards always win (highest priority)
-  2. Trump cards beat suit cards and
-  off-suit cards
-  3. Suit cards beat off-suit cards
-  4. Higher numbers win within the same
-  category
-  5. Jokers never win
-*/
-func isHigherCard(newCard, currentHighest *card.Card, trumpSymbol, suitSymbol *card.Symbol) bool {
-	// Trump cards beat suit cards and off-suit cards
-	newIsTrump := newCard.Symbol == *trumpSymbol
-	currentIsTrump := currentHighest.Symbol == *trumpSymbol
-
-	//2. Trump cards beat suit cards and off-suit cards
-	if newIsTrump && !currentIsTrump {
-		return true
-	}
-	if !newIsTrump && currentIsTrump {
-		return false
-	}
-
-	// If both are trump or both are not trump, compare by suit and number
-	newIsSuit := newCard.Symbol == *suitSymbol
-	currentIsSuit := currentHighest.Symbol == *suitSymbol
-
-	// Suit cards beat off-suit cards
-	if newIsSuit && !currentIsSuit {
-		return true
-	}
-	if !newIsSuit && currentIsSuit {
-		return false
-	}
-
-	// If same type (both trump, both suit, or both off-suit), higher number wins
-	return newCard.Number > currentHighest.Number
+	state       *engine.State // the rules of the turn, shared with the AI
+	agents      map[*player.Player]ai.Agent
+	log         func(engine.Event)  // sees every event of the turn
+	explain     bool                // show why the AI seats decide
+	note        func(*ai.Rationale) // told why an AI seat decided, before the decision is carried out
+	tutor       *ai.Tutor           // advises the human seats, nil when off
 }
 
 func (turn *Turn) Run(players player.Players, numberOfRounds int, dealerPos int) {
@@ -75,15 +46,38 @@ func (turn *Turn) Run(players player.Players, numberOfRounds int, dealerPos int)
 	turnDeck.Shuffle()
 
 	// Step 1 - each player gets N cards (N = number of rounds )
-	for _, player := range players {
-		player.DrawCards(&turnDeck, numberOfRounds)
+	// Step 2 - place the trump. At the final turn all cards are dealt and there is no trump
+	turn.state = engine.NewState(len(players), dealerPos, numberOfRounds, turnDeck)
+	turn.syncHands(players)
+
+	if turn.log != nil {
+		turn.state.Observe(engine.Everyone, turn.log)
 	}
 
-	// Step 2 - place the trump
-	turn.trump = turnDeck.Draw(1)[0]
-	fmt.Print("The trump is: ")
-	turn.trump.Show()
-	fmt.Println()
+	// AI seats following the turn learn about their own hand only
+	for pos, p := range players {
+		if observer, ok := turn.agents[p].(ai.Observer); ok {
+			turn.state.Observe(pos, observer.Observe)
+		}
+	}
+
+	turn.trump = turn.state.Trump
+	if turn.trump != (card.Card{}) {
+		fmt.Print("The trump is: ")
+		turn.trump.Show()
+		fmt.Println()
+	} else {
+		fmt.Println("All cards are dealt: there is no trump this turn.")
+	}
+
+	// A Wizard turned up lets the dealer name the trump
+	if turn.state.Phase == engine.Choosing {
+		dealer := players[dealerPos]
+		action := turn.decide(dealer, func() engine.Action {
+			return engine.TrumpOf(askTrump(dealer, len(players)))
+		})
+		fmt.Printf("%s names %s as the trump.\n", dealer.Label(), action.Suit)
+	}
 
 	// Step 3 - each player makes a prediction
 	AskPredictions(turn, dealerPos, players)
@@ -94,74 +88,213 @@ func (turn *Turn) Run(players player.Players, numberOfRounds int, dealerPos int)
 		if prediction.Player == nil {
 			continue
 		}
-		fmt.Printf("Player '%s' Predicts: %d tricks\n", (*prediction.Player).Name, prediction.PredictedTricks)
+		fmt.Printf("Player '%s' Predicts: %d tricks\n", prediction.Player.Label(), prediction.PredictedTricks)
 	}
 
-	for i := 0; i < numberOfRounds; i++ {
+	// The player after the dealer leads the first round, the tricker leads the next ones
+	for r := 0; r < numberOfRounds; r++ {
+		fmt.Printf("\nStarting round %d", r+1)
 		field := deck.Deck{}
 		round := Round{Trump: &turn.trump}
 
-		for i, _ := range players {
-			fmt.Printf("\nStarting round %d", i+1)
-			currPlayer := players[internals.GetPlayerPos(i, dealerPos, len(players))]
-			var selected int = -1
+		for range players {
+			pos := turn.state.ToAct
+			currPlayer := players[pos]
 
-			for selected == -1 {
-				fmt.Printf("\n%s, it's your turn. Here is your hand\n", currPlayer.Name)
-				for index, card := range currPlayer.Hand {
-					fmt.Printf("[%d] - ", index)
-					card.Show()
-				}
-				fmt.Printf("\n Type the number corresponding to the card you want to play: ")
-				fmt.Scan(&selected)
-				if selected < 0 || selected > len(currPlayer.Hand)-1 {
-					fmt.Printf("\n The selected card '%d' is not valid. Please try again.", selected)
-					selected = -1
-				}
+			// Wizard/Joker handling lives in the engine, shared with the AI
+			selectedCard := turn.decide(currPlayer, func() engine.Action {
+				return engine.PlayOf(currPlayer.Hand[turn.askCard(currPlayer, pos)])
+			}).Card
+			turn.syncHands(players)
 
-			}
-			fmt.Printf("%s played: ", currPlayer.Name)
-			selectedCard := currPlayer.Hand[selected]
+			fmt.Printf("\n%s played: ", currPlayer.Label())
 			selectedCard.Show()
+			field.Add(&selectedCard)
 
-			if i == 0 || round.Suit == nil {
-				round.Highest = &selectedCard
+			trick := turn.currentTrick()
+			if round.Suit == nil && trick.Suit != "" {
 				round.Suit = &selectedCard
-				round.Tricker = currPlayer
 				fmt.Print("\t\t \n\nThe Suit Is: ")
 				round.Suit.Show()
 				fmt.Printf("\n\n")
 			}
-
-			field.Add(&selectedCard)
-
-			// Handle card comparison and update highest card
-			if selectedCard.IsWizard {
-				// Wizard always wins
-				round.Tricker = currPlayer
-				round.Highest = &selectedCard
-			} else if selectedCard.IsJoker {
-				// Joker never wins, skip comparison
-			} else if round.Highest.IsJoker {
-				// Any non-joker beats a joker
-				round.Tricker = currPlayer
-				round.Highest = &selectedCard
-			} else if !round.Highest.IsWizard {
-				// Only compare if current highest is not a wizard
-				if isHigherCard(&selectedCard, round.Highest, &turn.trump.Symbol, &round.Suit.Symbol) {
-					round.Tricker = currPlayer
-					round.Highest = &selectedCard
-				}
-			}
+			round.Highest = trick.Highest()
+			round.Tricker = players[trick.Winner()]
 		}
 
 		fmt.Printf("\n\nCards on the table: ")
 		field.Show()
 
-		fmt.Printf("\n\nThe tricker for this round is: %s with the card ", round.Tricker.Name)
+		fmt.Printf("\n\nThe tricker for this round is: %s with the card ", round.Tricker.Label())
 		round.Highest.Show()
 		fmt.Printf("\n\n")
 
+		turn.rounds[r] = round
+		turn.predictionOf(round.Tricker).Outcome++
+	}
+
+	// Step 4 - score the predictions
+	fmt.Println("Here are the results of the turn:")
+	for _, prediction := range turn.predictions {
+		points := engine.Score(prediction.PredictedTricks, prediction.Outcome)
+		prediction.Player.Score += points
+		fmt.Printf("Player '%s' predicted %d and made %d tricks: %+d points (total %d)\n",
+			prediction.Player.Label(), prediction.PredictedTricks, prediction.Outcome, points, prediction.Player.Score)
+	}
+
+}
+
+// decide carries out the decision of the seat of p to act: its agent's for
+// an AI seat, ask's for a human. A human is asked again when the rules don't
+// allow the decision. An AI seat falls back on its built-in heuristic, as a
+// bot, a clone or a strategy table may answer with a card the seat doesn't
+// hold.
+func (turn *Turn) decide(p *player.Player, ask func() engine.Action) engine.Action {
+	if !p.IsAI {
+		for {
+			action := ask()
+			err := turn.state.Apply(action)
+			if err == nil {
+				return action
+			}
+			fmt.Printf("\n %v. Please try again.\n", err)
+		}
 	}
 
+	action := arena.Decide(turn.agents[p], turn.state)
+	turn.explained(p)
+	if err := turn.state.Apply(action); err != nil {
+		fmt.Printf("\n%s can't do that (%v) and plays as the %s level instead.", p.Label(), err, ai.Medium)
+		heuristic := ai.New(ai.Medium, ai.PersonalityFor(p.Profile), 0)
+		action = arena.Decide(heuristic, turn.state)
+		if err := turn.state.Apply(action); err != nil {
+			action = turn.state.LegalActions()[0]
+			if err := turn.state.Apply(action); err != nil {
+				// the engine refusing one of its own legal actions is a bug
+				panic(fmt.Errorf("the engine refused its legal action %+v: %w", action, err))
+			}
+		}
+	}
+	return action
+}
+
+// explained shows and notes why the AI seat of p took its last decision
+func (turn *Turn) explained(p *player.Player) {
+	explainer, ok := turn.agents[p].(ai.Explainer)
+	if !ok || explainer.Rationale() == nil {
+		return
+	}
+	rationale := explainer.Rationale()
+	if turn.explain {
+		fmt.Printf("\nWhy %s decided:\n%s\n", p.Label(), rationale)
+	}
+	if turn.note != nil {
+		turn.note(rationale)
+	}
+}
+
+// currentTrick is the round being played, or the one just completed
+func (turn *Turn) currentTrick() *engine.Trick {
+	if len(turn.state.Trick.Cards) > 0 {
+		return &turn.state.Trick
+	}
+	return &turn.state.History[len(turn.state.History)-1]
+}
+
+// syncHands shows the players the hands the engine holds for them
+func (turn *Turn) syncHands(players player.Players) {
+	for pos, p := range players {
+		p.Hand = turn.state.Hands[pos]
+	}
+}
+
+// askCard prompts a human player until a card that may be played is chosen.
+// The tutor, when on, marks the cards taking the trick, suggests one and
+// asks before a play that looks like a mistake.
+func (turn *Turn) askCard(currPlayer *player.Player, pos int) int {
+	var selected int = -1
+	trick := &turn.state.Trick
+	view := turn.state.View(pos)
+
+	var advice ai.PlayAdvice
+	if turn.tutor != nil {
+		advice = turn.tutor.Play(&view)
+	}
+
+	for selected == -1 {
+		fmt.Printf("\n%s, it's your turn. Here is your hand\n", currPlayer.Name)
+		for index, card := range currPlayer.Hand {
+			fmt.Printf("[%d] - ", index)
+			card.Show()
+			if slices.Contains(advice.Winning, card) {
+				fmt.Print(" takes the trick")
+			}
+			if turn.tutor != nil {
+				fmt.Println()
+			}
+		}
+		if turn.tutor != nil {
+			fmt.Printf("Tutor: play %s (%s); %s", advice.Card, advice.Why, advice.Out)
+		}
+		fmt.Printf("\n Type the number corresponding to the card you want to play: ")
+		fmt.Scan(&selected)
+		if selected < 0 || selected > len(currPlayer.Hand)-1 {
+			fmt.Printf("\n The selected card '%d' is not valid. Please try again.", selected)
+			selected = -1
+		} else if !trick.CanPlay(currPlayer.Hand, currPlayer.Hand[selected]) {
+			fmt.Printf("\n You must follow the suit (%s). Please try again.", trick.Suit)
+			selected = -1
+		} else if turn.tutor != nil {
+			if warning := turn.tutor.Warn(&view, currPlayer.Hand[selected]); warning != "" {
+				var answer string
+				fmt.Printf("\n Tutor: %s. Play it anyway? [y/N] ", warning)
+				fmt.Scan(&answer)
+				if !strings.EqualFold(answer, "y") {
+					selected = -1
+				}
+			}
+		}
+	}
+
+	return selected
+}
+
+// askTrump prompts a human dealer for the trump, with a hint on how the hand
+// fares with every symbol
+func askTrump(dealer *player.Player, numPlayers int) card.Symbol {
+	options := ai.EvaluateTrumps(dealer.Hand, numPlayers)
+
+	fmt.Printf("\n%s, a Wizard was turned up: you name the trump. Here is your hand\n", dealer.Name)
+	for _, c := range dealer.Hand {
+		c.Show()
+	}
+	fmt.Println()
+	for i, symbol := range card.Symbols {
+		for _, option := range options {
+			if option.Symbol == symbol {
+				fmt.Printf("[%d] - %s: about %.1f tricks, bid %d for %+.0f points\n", i, symbol, option.Tricks, option.Bid, option.Score)
+			}
+		}
+	}
+	fmt.Printf("Hint: %s looks best.\n", options[0].Symbol)
+
+	selected := -1
+	for selected == -1 {
+		fmt.Printf("Type the number corresponding to the trump you want: ")
+		fmt.Scan(&selected)
+		if selected < 0 || selected >= len(card.Symbols) {
+			fmt.Printf("\n The selected trump '%d' is not valid. Please try again.\n", selected)
+			selected = -1
+		}
+	}
+	return card.Symbols[selected]
+}
+
+func (turn *Turn) predictionOf(p *player.Player) *Prediction {
+	for i := range turn.predictions {
+		if turn.predictions[i].Player == p {
+			return &turn.predictions[i]
+		}
+	}
+	return nil
 }
@@ -2,29 +2,36 @@ package game
 
 import (
 	"fmt"
+	"wizard/engine"
 	"wizard/game/internals"
 	"wizard/player"
 )
 
 func AskPredictions(turn *Turn, dealerPos int, players player.Players) {
-	predictions := make([]Prediction, len(players))
+	turn.predictions = make([]Prediction, len(players))
 
 	for i := 0; i < len(players); i++ {
-		currPlayer := players[internals.GetPlayerPos(i, dealerPos, len(players))]
+		pos := internals.GetPlayerPos(i, dealerPos, len(players))
+		currPlayer := players[pos]
 
-		if !currPlayer.IsAI {
+		// AI seats see the predictions made before theirs
+		prediction := turn.decide(currPlayer, func() engine.Action {
+			var prediction int
 			fmt.Printf("\n%s, here's your hand:\n", currPlayer.Name)
 			currPlayer.ShowHand()
+			if turn.tutor != nil {
+				view := turn.state.View(pos)
+				fmt.Printf("\nTutor: %s", turn.tutor.Bid(&view))
+			}
 			fmt.Print("\nMake a prediction of tricks: ")
-			var prediction int
 			fmt.Scan(&prediction)
-			predictions[i] = Prediction{Player: currPlayer, PredictedTricks: prediction}
-		} else {
-
-		}
-
+			for prediction < 0 || prediction > len(currPlayer.Hand) {
+				fmt.Printf("\nA prediction must be between 0 and %d. Please try again: ", len(currPlayer.Hand))
+				fmt.Scan(&prediction)
+			}
+			return engine.BidOf(prediction)
+		}).Bid
+		turn.predictions[i] = Prediction{Player: currPlayer, PredictedTricks: prediction}
 	}
 
-	turn.predictions = predictions
-
 }
@@ -0,0 +1,148 @@
+// Package gamelog keeps the games played on disk, one JSON record per line,
+// for the tools that learn from them and analyse them.
+package gamelog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"wizard/ai"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// Game is the record of one game: who sat where and every event of every
+// turn, as observed by engine.Everyone.
+type Game struct {
+	Players []string       `json:"players"`
+	Agents  []string       `json:"agents"` // the AI level of every seat, "bot:<name>" for a bot, empty for a human
+	Events  []engine.Event `json:"events"`
+	// why the AI seats took their decisions
+	Explanations []Explanation `json:"explanations,omitempty"`
+}
+
+// Explanation is the rationale of the decision told by Events[Event].
+type Explanation struct {
+	Event int `json:"event"`
+	ai.Rationale
+}
+
+// Append adds a game at the end of the log, creating the file if needed.
+func Append(path string, game Game) error {
+	data, err := json.Marshal(game)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Read returns every game of the log; a log not written yet has none.
+func Read(path string) ([]Game, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var games []Game
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var game Game
+		if err := json.Unmarshal(scanner.Bytes(), &game); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		games = append(games, game)
+	}
+	return games, scanner.Err()
+}
+
+// Decision is a decision taken in a logged game, with the turn as it stood
+// when it was taken.
+type Decision struct {
+	Turn   int // counting from 0
+	Event  int // the index of the event telling it
+	State  *engine.State
+	Action engine.Action
+}
+
+// Replay deals every turn of the game again from its events and calls fn with
+// every trump named, bid and card played, in order. fn owns the state it is
+// given. A log that does not play out by the rules is an error.
+func (g Game) Replay(fn func(Decision)) error {
+	var (
+		state *engine.State
+		turn  = -1
+	)
+	for i, e := range g.Events {
+		var action engine.Action
+		switch e.Kind {
+		case engine.DealEvent:
+			if e.Seat == 0 {
+				turn++
+				state = &engine.State{
+					Dealer:   e.Dealer,
+					HandSize: e.HandSize,
+					Hands:    make([][]card.Card, e.Players),
+					Bids:     make([]int, e.Players),
+					Won:      make([]int, e.Players),
+				}
+				for seat := range state.Bids {
+					state.Bids[seat] = -1
+				}
+			}
+			if state == nil {
+				return fmt.Errorf("event %d: a deal to seat %d opens no turn", i, e.Seat)
+			}
+			state.Hands[e.Seat] = slices.Clone(e.Hand)
+			continue
+		case engine.TrumpEvent:
+			if state == nil {
+				return fmt.Errorf("event %d: trump before the deal", i)
+			}
+			if state.Phase != engine.Choosing {
+				state.Trump, state.TrumpSuit = e.Card, e.Suit
+				state.Trick = engine.NewTrick(e.Suit)
+				state.ToAct = (e.Dealer + 1) % e.Players
+				if e.Card.IsWizard && e.Suit == "" {
+					state.Phase, state.ToAct = engine.Choosing, e.Dealer
+				}
+				continue
+			}
+			action = engine.TrumpOf(e.Suit)
+		case engine.BidEvent:
+			action = engine.BidOf(e.Bid)
+		case engine.PlayEvent:
+			action = engine.PlayOf(e.Card)
+		default:
+			continue
+		}
+
+		if state == nil || state.ToAct != e.Seat {
+			return fmt.Errorf("event %d: seat %d decides out of turn", i, e.Seat)
+		}
+		fn(Decision{Turn: turn, Event: i, State: state.Clone(), Action: action})
+		if err := state.Apply(action); err != nil {
+			return fmt.Errorf("event %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -1,8 +1,16 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"wizard/ai"
 	"wizard/game"
 	"wizard/player"
+	"wizard/protocol"
+	"wizard/rating"
 )
 
 var Reset = "\033[0m"
@@ -15,10 +23,187 @@ var Cyan = "\033[36m"
 var Gray = "\033[37m"
 var White = "\033[97m"
 
+// commands are run as "wizard <command> [flags]"; with no command a game is played.
+var commands = map[string]func(args []string){
+	"tune":         runTune,
+	"train-bidder": runTrainBidder,
+	"review":       runReview,
+	"endgame":      runEndgame,
+	"cfr":          runCFR,
+	"bid-tables":   runBidTables,
+	"clone":        runClone,
+	"tournament":   runTournament,
+	"ratings":      runRatings,
+}
+
 func main() {
-	players := player.Register([]string{"Dario", "Angela"}, 2)
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			command(os.Args[2:])
+			return
+		}
+	}
+
+	names := flag.String("players", "Dario,Angela", "comma separated names of the human players")
+	numberOfAI := flag.Int("ai", 2, "number of computer players")
+	profiles := flag.String("profiles", "", "comma separated profile of each computer player: conservative, balanced or aggressive")
+	levels := flag.String("levels", "", "comma separated difficulty of each computer player: easy, medium, hard, expert, learned, cfr, clone or ismcts")
+	flag.IntVar(&ai.ExpertSearch.Iterations, "expert-iterations", ai.ExpertSearch.Iterations, "deals an expert player samples per decision")
+	flag.DurationVar(&ai.ExpertSearch.Budget, "expert-budget", ai.ExpertSearch.Budget, "time an expert player may think per decision, 0 for no limit")
+	flag.IntVar(&ai.DefaultTreeSearch.Iterations, "ismcts-iterations", ai.DefaultTreeSearch.Iterations, "iterations an ismcts player searches per decision")
+	flag.DurationVar(&ai.DefaultTreeSearch.Budget, "ismcts-budget", ai.DefaultTreeSearch.Budget, "time an ismcts player may think per decision, 0 for no limit")
+	flag.IntVar(&ai.ExpertEndgame.Tricks, "endgame-tricks", ai.ExpertEndgame.Tricks, "tricks left from which an expert player solves the turn exactly, 0 never to")
+	flag.IntVar(&ai.ExpertEndgame.Unknown, "endgame-unknown", ai.ExpertEndgame.Unknown, "most cards an expert player may not have seen to solve the turn exactly")
+	opponents := flag.String("opponents", "", "file keeping what the computer players learned of everyone between games; without one it is forgotten")
+	ratings := flag.String("ratings", "", "file keeping the ratings of the human players and the AI configurations; without one no one is rated")
+	log := flag.String("log", "", "game log every game is added to; without one none is kept")
+	weights := flag.String("weights", "", "weights profile saved by 'wizard tune' for the computer players")
+	bidder := flag.String("bidder", "", "bid model saved by 'wizard train-bidder' for the learned players; without one they bid as medium")
+	bidTables := flag.String("bid-tables", "", "bid tables saved by 'wizard bid-tables' the hard and expert players look their bids up in")
+	imitate := flag.String("imitate", "", "clone saved by 'wizard clone' the clone players imitate")
+	strategy := flag.String("strategy", "", "strategy saved by 'wizard cfr' for the cfr players; without one they play as medium")
+	bots := flag.String("bots", "", "comma separated command of the bot playing each computer player, empty for the built-in AI")
+	botTimeout := flag.Duration("bot-timeout", protocol.DefaultTimeout, "time a bot may think per decision before its seat falls back to the built-in AI")
+	explain := flag.Bool("explain", false, "show why the computer players take their decisions")
+	tutor := flag.Bool("tutor", false, "advise the human players on their predictions and cards")
+	botOptions := flag.String("bot-options", "", "comma separated name=value options set on every bot")
+	flag.Parse()
+
+	if *weights != "" {
+		w, err := ai.LoadWeights(*weights)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		ai.DefaultWeights = w
+	}
+	if *bidder != "" {
+		model, err := ai.LoadBidModel(*bidder)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		ai.BidderModel = model
+	}
+	if *bidTables != "" {
+		table, err := ai.LoadBidTable(*bidTables)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		ai.BidTables = table
+	}
+	if *imitate != "" {
+		clone, err := ai.LoadClone(*imitate)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		ai.CloneModel = clone
+	}
+	if *strategy != "" {
+		table, err := ai.LoadStrategy(*strategy)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		ai.CFRStrategy = table
+	}
+
+	aiProfiles := splitList(*profiles)
+	for _, profile := range aiProfiles {
+		if _, err := ai.PersonalityByName(profile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	players := player.Register(splitList(*names), *numberOfAI, aiProfiles...)
+
+	aiLevels := splitList(*levels)
+	aiSeats := players[len(players)-*numberOfAI:]
+	for i, name := range aiLevels {
+		level, err := ai.LevelByName(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if i < len(aiSeats) {
+			aiSeats[i].Level = string(level)
+		}
+	}
 
 	game := game.InitGame(players)
+	game.Log = *log
+	game.Explain = *explain
+	game.Tutor = *tutor
+
+	// Bots play their seats on the built-in AI of the seat once they fail
+	options := map[string]string{}
+	for _, option := range splitList(*botOptions) {
+		name, value, _ := strings.Cut(option, "=")
+		options[name] = value
+	}
+	var external []*protocol.ExternalAgent
+	for i, command := range strings.Split(*bots, ",") {
+		if strings.TrimSpace(command) == "" || i >= len(aiSeats) {
+			continue
+		}
+		p := aiSeats[i]
+		level, _ := ai.LevelByName(p.Level)
+		bot, err := protocol.Start(strings.Fields(command), options, *botTimeout, ai.New(level, ai.PersonalityFor(p.Profile), rand.Int63()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v: %s plays as the built-in AI\n", err, p.Name)
+			continue
+		}
+		game.Assign(p, bot)
+		external = append(external, bot)
+	}
+
+	if *opponents != "" {
+		book, err := ai.LoadOpponentBook(*opponents)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		game.Read(book)
+	}
+	if *ratings != "" {
+		book, err := rating.Load(*ratings)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		game.Ratings = book
+	}
 	game.Run(0)
 
+	for _, bot := range external {
+		if bot.Fault() != nil {
+			fmt.Fprintf(os.Stderr, "bot %s was stopped: %v\n", bot.Name, bot.Fault())
+		}
+		bot.Close()
+	}
+
+	if game.Opponents != nil {
+		if err := game.Opponents.Save(*opponents); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+	if game.Ratings != nil {
+		if err := game.Ratings.Save(*ratings); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -2,6 +2,7 @@ package player
 
 import (
 	"fmt"
+	"strings"
 	"wizard/card"
 	"wizard/deck"
 )
@@ -11,6 +12,10 @@ type Player struct {
 	Score int
 	Hand  []card.Card
 	IsAI  bool
+	// Profile names the personality of an AI seat (see ai.Personalities)
+	Profile string
+	// Level is the difficulty of an AI seat (see ai.Levels)
+	Level string
 }
 
 type Players []*Player
@@ -27,7 +32,27 @@ func (p *Player) ShowHand() {
 	}
 }
 
-func Register(playerNames []string, numberOfAI int) Players {
+// Label is the name shown in logs: AI seats carry their level and profile
+func (p *Player) Label() string {
+	if !p.IsAI {
+		return p.Name
+	}
+	var traits []string
+	for _, trait := range []string{p.Level, p.Profile} {
+		if trait != "" {
+			traits = append(traits, trait)
+		}
+	}
+	if len(traits) == 0 {
+		return p.Name
+	}
+	return fmt.Sprintf("%s (%s)", p.Name, strings.Join(traits, ", "))
+}
+
+// Register creates the human players followed by numberOfAI computer players.
+// aiProfiles assigns a profile to each AI seat in order; seats without one are "balanced".
+// Every AI seat starts at the "medium" level.
+func Register(playerNames []string, numberOfAI int, aiProfiles ...string) Players {
 	numberOfPlayers := len(playerNames) + numberOfAI
 	players := make(Players, numberOfPlayers)
 
@@ -39,10 +64,17 @@ func Register(playerNames []string, numberOfAI int) Players {
 				IsAI:  false,
 			}
 		} else {
+			aiIndex := index - len(playerNames)
+			profile := "balanced"
+			if aiIndex < len(aiProfiles) && aiProfiles[aiIndex] != "" {
+				profile = aiProfiles[aiIndex]
+			}
 			players[index] = &Player{
-				Name:  fmt.Sprintf("Computer %d", index-len(playerNames)+1),
-				Score: 0,
-				IsAI:  true,
+				Name:    fmt.Sprintf("Computer %d", aiIndex+1),
+				Score:   0,
+				IsAI:    true,
+				Profile: profile,
+				Level:   "medium",
 			}
 		}
 
@@ -0,0 +1,56 @@
+package protocol
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Echo is the reference bot: it answers every decision with the first legal
+// move, or the last one when its "pick" option is set to last. It reads the
+// game from in and answers on out until the game quits.
+func Echo(in io.Reader, out io.Writer) error {
+	var legal []string
+	pick := "first"
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		var answer string
+		switch fields[0] {
+		case "wizard":
+			answer = "id name Echo\noption name pick default first\nwizardok"
+		case "setoption":
+			if len(fields) == 5 && fields[2] == "pick" {
+				pick = fields[4]
+			}
+		case "isready":
+			answer = "readyok"
+		case "legal":
+			legal = fields[1:]
+		case "go":
+			if len(fields) < 2 || len(legal) == 0 {
+				return fmt.Errorf("asked to decide with no legal move")
+			}
+			move := legal[0]
+			if pick == "last" {
+				move = legal[len(legal)-1]
+			}
+			answer = fields[1] + " " + move
+		case "quit":
+			return nil
+		}
+
+		if answer != "" {
+			if _, err := fmt.Fprintln(out, answer); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,325 @@
+package protocol
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+	"wizard/ai"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// Time a bot may take, by default, over a decision and over the handshake
+const (
+	DefaultTimeout   = 2 * time.Second
+	HandshakeTimeout = 10 * time.Second
+)
+
+// Option is a setting a bot declared in the handshake.
+type Option struct {
+	Name    string
+	Default string
+}
+
+// ExternalAgent takes the decisions of a seat by asking a bot program. Once
+// the bot crashes, runs out of time or breaks the protocol it is stopped,
+// and the seat is played by the fallback agent for the rest of the game.
+type ExternalAgent struct {
+	Name    string   // as the bot introduced itself
+	Options []Option // the settings the bot takes
+	Timeout time.Duration
+
+	fallback ai.Agent
+	cmd      *exec.Cmd
+	in       io.WriteCloser
+	lines    chan string
+	done     chan struct{}
+	exited   chan error // the bot's exit, once waited for
+	fault    error
+	last     *ai.Rationale
+}
+
+// Start launches the bot and goes through the handshake, setting the given
+// options. timeout bounds every decision and every write to the bot,
+// DefaultTimeout if 0.
+func Start(command []string, options map[string]string, timeout time.Duration, fallback ai.Agent) (*ExternalAgent, error) {
+	if len(command) == 0 {
+		return nil, errors.New("no bot command")
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	e := &ExternalAgent{
+		Name:     command[0],
+		Timeout:  timeout,
+		fallback: fallback,
+		cmd:      cmd,
+		in:       in,
+		lines:    make(chan string),
+		done:     make(chan struct{}),
+	}
+	go e.read(out)
+
+	if err := e.handshake(options); err != nil {
+		e.fail(err)
+		return nil, fmt.Errorf("bot %s: %w", command[0], err)
+	}
+	return e, nil
+}
+
+// read passes the bot's lines on, leaving out the blank and the info ones.
+func (e *ExternalAgent) read(out io.Reader) {
+	defer close(e.lines)
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line == "info" || strings.HasPrefix(line, "info ") {
+			continue
+		}
+		select {
+		case e.lines <- line:
+		case <-e.done:
+			return
+		}
+	}
+}
+
+func (e *ExternalAgent) handshake(options map[string]string) error {
+	if err := e.send(fmt.Sprintf("wizard %d", Version)); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(HandshakeTimeout)
+	for {
+		line, err := e.expect(time.Until(deadline))
+		if err != nil {
+			return err
+		}
+		if line == "wizardok" {
+			break
+		}
+		switch fields := strings.Fields(line); {
+		case len(fields) > 2 && fields[0] == "id" && fields[1] == "name":
+			e.Name = strings.Join(fields[2:], " ")
+		case len(fields) > 2 && fields[0] == "option" && fields[1] == "name":
+			option := Option{Name: fields[2]}
+			if len(fields) > 4 && fields[3] == "default" {
+				option.Default = strings.Join(fields[4:], " ")
+			}
+			e.Options = append(e.Options, option)
+		}
+	}
+
+	for name, value := range options {
+		if err := e.send(fmt.Sprintf("setoption name %s value %s", name, value)); err != nil {
+			return err
+		}
+	}
+	if err := e.send("isready"); err != nil {
+		return err
+	}
+	for {
+		line, err := e.expect(time.Until(deadline))
+		if err != nil {
+			return err
+		}
+		if line == "readyok" {
+			return nil
+		}
+	}
+}
+
+func (e *ExternalAgent) send(line string) error {
+	return e.write(line + "\n")
+}
+
+// write hands text to the bot, giving up when the bot doesn't take it within
+// its timeout. The write left behind ends once the bot is stopped.
+func (e *ExternalAgent) write(text string) error {
+	written := make(chan error, 1)
+	go func() {
+		_, err := io.WriteString(e.in, text)
+		written <- err
+	}()
+	timer := time.NewTimer(e.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-written:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("the bot read nothing within %v", e.Timeout)
+	}
+}
+
+// expect waits for the next line of the bot.
+func (e *ExternalAgent) expect(timeout time.Duration) (string, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case line, ok := <-e.lines:
+		if !ok {
+			return "", errors.New("the bot quit")
+		}
+		return line, nil
+	case <-timer.C:
+		return "", fmt.Errorf("no answer within %v", timeout)
+	}
+}
+
+// fail stops the bot for good.
+func (e *ExternalAgent) fail(err error) {
+	if e.fault != nil {
+		return
+	}
+	e.fault = err
+	close(e.done)
+	e.in.Close()
+	e.cmd.Process.Kill()
+	<-e.wait()
+}
+
+// wait waits for the bot to exit, on the first call, and returns the
+// channel its exit comes on.
+func (e *ExternalAgent) wait() <-chan error {
+	if e.exited == nil {
+		e.exited = make(chan error, 1)
+		go func() { e.exited <- e.cmd.Wait() }()
+	}
+	return e.exited
+}
+
+// Fault returns why the bot was stopped, nil while it plays the seat.
+func (e *ExternalAgent) Fault() error {
+	return e.fault
+}
+
+// Close tells the bot the game is over and stops it.
+func (e *ExternalAgent) Close() error {
+	if e.fault != nil {
+		return nil
+	}
+	e.send("quit")
+	e.in.Close()
+
+	select {
+	case err := <-e.wait():
+		e.fault = errors.New("the game is over")
+		close(e.done)
+		return err
+	case <-time.After(time.Second):
+		e.fail(errors.New("the game is over"))
+		return nil
+	}
+}
+
+// decide asks the bot, falling back when it can't answer.
+func (e *ExternalAgent) decide(view *engine.PlayerView, decision Decision) (engine.Action, bool) {
+	if e.fault != nil {
+		return engine.Action{}, false
+	}
+	var request strings.Builder
+	WriteDecision(&request, view, decision, e.Timeout.Milliseconds())
+	if err := e.write(request.String()); err != nil {
+		e.fail(err)
+		return engine.Action{}, false
+	}
+	line, err := e.expect(e.Timeout)
+	if err != nil {
+		e.fail(err)
+		return engine.Action{}, false
+	}
+	action, err := ParseAnswer(line, view, decision)
+	if err != nil {
+		e.fail(err)
+		return engine.Action{}, false
+	}
+	e.last = &ai.Rationale{Decision: ai.Describe(action), Rule: "the bot " + e.Name + " answered"}
+	return action, true
+}
+
+// fellBack takes the rationale of the fallback agent's decision.
+func (e *ExternalAgent) fellBack() {
+	e.last = nil
+	if explainer, ok := e.fallback.(ai.Explainer); ok && explainer.Rationale() != nil {
+		r := *explainer.Rationale()
+		r.Rule = fmt.Sprintf("the bot was stopped (%v), %s", e.fault, r.Rule)
+		e.last = &r
+	}
+}
+
+func (e *ExternalAgent) Bid(view *engine.PlayerView) int {
+	if action, ok := e.decide(view, Bid); ok {
+		return action.Bid
+	}
+	bid := e.fallback.Bid(view)
+	e.fellBack()
+	return bid
+}
+
+func (e *ExternalAgent) Play(view *engine.PlayerView) card.Card {
+	if action, ok := e.decide(view, Play); ok {
+		return action.Card
+	}
+	c := e.fallback.Play(view)
+	e.fellBack()
+	return c
+}
+
+func (e *ExternalAgent) Trump(view *engine.PlayerView) card.Symbol {
+	if action, ok := e.decide(view, Trump); ok {
+		return action.Suit
+	}
+	symbol := e.fallback.Trump(view)
+	e.fellBack()
+	return symbol
+}
+
+// Explain has the fallback agent explain the decisions it takes over; of the
+// bot's own only the answer is known.
+func (e *ExternalAgent) Explain() {
+	if explainer, ok := e.fallback.(ai.Explainer); ok {
+		explainer.Explain()
+	}
+}
+
+func (e *ExternalAgent) Rationale() *ai.Rationale {
+	return e.last
+}
+
+// Observe keeps the fallback agent following the turn, ready to take over.
+func (e *ExternalAgent) Observe(event engine.Event) {
+	if observer, ok := e.fallback.(ai.Observer); ok {
+		observer.Observe(event)
+	}
+}
+
+// Read gives the fallback agent the profiles of the players at the table.
+func (e *ExternalAgent) Read(opponents []*ai.OpponentProfile) {
+	if reader, ok := e.fallback.(ai.Reader); ok {
+		reader.Read(opponents)
+	}
+}
+
+// Standings keeps the fallback agent told how the game stands.
+func (e *ExternalAgent) Standings(standings ai.Standings) {
+	if contender, ok := e.fallback.(ai.Contender); ok {
+		contender.Standings(standings)
+	}
+}
@@ -0,0 +1,74 @@
+package protocol_test
+
+import (
+	"math/rand"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/protocol"
+)
+
+// TestExternalAgent seats the reference echo bot, and bots that crash or
+// stall, which have to hand their seat over to the built-in AI
+func TestExternalAgent(t *testing.T) {
+	echo := t.TempDir() + "/echobot"
+	if out, err := exec.Command("go", "build", "-o", echo, "../bots/echo").CombinedOutput(); err != nil {
+		t.Fatalf("building the echo bot: %v\n%s", err, out)
+	}
+
+	bot, err := protocol.Start([]string{echo}, map[string]string{"pick": "last"}, time.Second, ai.New(ai.Medium, ai.Balanced, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bot.Name != "Echo" || len(bot.Options) != 1 || bot.Options[0].Name != "pick" {
+		t.Errorf("handshake gave %q with options %v", bot.Name, bot.Options)
+	}
+	agents := []ai.Agent{bot, ai.New(ai.Medium, ai.Balanced, 2), ai.New(ai.Medium, ai.Balanced, 3)}
+	result := arena.Play(agents, 0, rand.New(rand.NewSource(5)))
+	if bot.Fault() != nil {
+		t.Fatalf("the echo bot was stopped: %v", bot.Fault())
+	}
+	for turn, bids := range result.Bids {
+		// The last legal bid is the whole hand
+		if bids[0] != turn+1 {
+			t.Errorf("the echo bot bid %d on turn %d", bids[0], turn+1)
+		}
+	}
+	bot.Close()
+
+	for name, script := range map[string]string{
+		"crashing": "echo wizardok; read line; echo readyok; read line; exit 1",
+		"stalling": "echo wizardok; read line; echo readyok; exec sleep 10",
+	} {
+		bot, err := protocol.Start([]string{"sh", "-c", script}, nil, 100*time.Millisecond, ai.New(ai.Medium, ai.Balanced, 1))
+		if err != nil {
+			t.Fatalf("%s bot: %v", name, err)
+		}
+		agents := []ai.Agent{bot, ai.New(ai.Medium, ai.Balanced, 2), ai.New(ai.Medium, ai.Balanced, 3)}
+		arena.Play(agents, 0, rand.New(rand.NewSource(5)))
+		if bot.Fault() == nil {
+			t.Errorf("the %s bot was never stopped", name)
+		}
+	}
+
+	// A bot that stops reading is stopped rather than blocking the game
+	start := time.Now()
+	_, err = protocol.Start([]string{"sh", "-c", "echo wizardok; exec sleep 10"}, map[string]string{"book": strings.Repeat("x", 1<<20)}, 100*time.Millisecond, ai.New(ai.Medium, ai.Balanced, 1))
+	if took := time.Since(start); err == nil || took > 5*time.Second {
+		t.Errorf("starting a bot that reads nothing took %v and gave %v", took, err)
+	}
+
+	// A bot ignoring the quit is killed once it had its second to exit
+	bot, err = protocol.Start([]string{"sh", "-c", "echo wizardok; read line; echo readyok; exec sleep 10"}, nil, time.Second, ai.New(ai.Medium, ai.Balanced, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start = time.Now()
+	bot.Close()
+	if took := time.Since(start); took > 5*time.Second || bot.Fault() == nil {
+		t.Errorf("closing a deaf bot took %v and left it %v", took, bot.Fault())
+	}
+}
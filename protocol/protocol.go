@@ -0,0 +1,175 @@
+// Package protocol lets a bot written in any language take an AI seat. The
+// bot is a program talking a line-based text protocol, in the manner of UCI,
+// over its standard input and output.
+//
+// The game opens with a handshake. The bot answers the protocol version with
+// its name and the options it takes, the game sets those it was given, and
+// waits until the bot is ready:
+//
+//	> wizard 1
+//	< id name Echo
+//	< id author Someone
+//	< option name style default first
+//	< wizardok
+//	> setoption name style value last
+//	> isready
+//	< readyok
+//
+// Every decision asked of the bot is a state block, the legal moves and a go
+// line naming the decision and the milliseconds the bot has to take it:
+//
+//	> state seat 1 players 4 dealer 0 handsize 3
+//	> trump E5 suit E
+//	> hand E13 W J
+//	> bids 1 - - -
+//	> won 0 0 0 0
+//	> history 1:H3 2:H5 3:W 0:J
+//	> trick 3:D7
+//	> legal E13 W J
+//	> go play 2000
+//	< play W
+//
+// Cards are written W for a Wizard, J for a Joker and otherwise as the
+// initial of the symbol followed by the number: H (Human), E (Elf),
+// D (Dwarf) and G (Giant). A dash stands for no trump card, no trump suit or
+// a seat that hasn't bid. history has one line per completed trick and trick
+// the cards played to the current one, each as seat:card in playing order;
+// both are left out while empty. The decisions are answered with
+//
+//	bid N
+//	play <card>
+//	trump <symbol>
+//
+// The bot may send "info <text>" lines at any time; they are ignored. The
+// game ends with "quit".
+package protocol
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// Version is the version of the protocol told in the handshake.
+const Version = 1
+
+// Decision is the kind of decision a go line asks for.
+type Decision string
+
+const (
+	Bid   Decision = "bid"
+	Play  Decision = "play"
+	Trump Decision = "trump"
+)
+
+// Legal returns the moves open to the view's seat for the decision.
+func Legal(view *engine.PlayerView, decision Decision) []string {
+	var moves []string
+	switch decision {
+	case Bid:
+		for bid := 0; bid <= len(view.Hand); bid++ {
+			moves = append(moves, strconv.Itoa(bid))
+		}
+	case Play:
+		for _, c := range view.Trick.LegalPlays(view.Hand) {
+			if !slices.Contains(moves, c.String()) {
+				moves = append(moves, c.String())
+			}
+		}
+	case Trump:
+		for _, symbol := range card.Symbols {
+			moves = append(moves, symbol.Initial())
+		}
+	}
+	return moves
+}
+
+// WriteDecision sends the state block, the legal moves and the go line.
+func WriteDecision(w io.Writer, view *engine.PlayerView, decision Decision, millis int64) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "state seat %d players %d dealer %d handsize %d\n", view.Seat, view.NumPlayers(), view.Dealer, view.HandSize)
+	suit := "-"
+	if view.TrumpSuit != "" {
+		suit = view.TrumpSuit.Initial()
+	}
+	fmt.Fprintf(&b, "trump %s suit %s\n", view.Trump, suit)
+	fmt.Fprintf(&b, "hand %s\n", joinCards(view.Hand))
+
+	bids := make([]string, len(view.Bids))
+	for seat, bid := range view.Bids {
+		bids[seat] = "-"
+		if bid >= 0 {
+			bids[seat] = strconv.Itoa(bid)
+		}
+	}
+	fmt.Fprintf(&b, "bids %s\n", strings.Join(bids, " "))
+	won := make([]string, len(view.Won))
+	for seat, tricks := range view.Won {
+		won[seat] = strconv.Itoa(tricks)
+	}
+	fmt.Fprintf(&b, "won %s\n", strings.Join(won, " "))
+
+	for _, trick := range view.History {
+		fmt.Fprintf(&b, "history %s\n", joinPlays(&trick))
+	}
+	if len(view.Trick.Cards) > 0 {
+		fmt.Fprintf(&b, "trick %s\n", joinPlays(&view.Trick))
+	}
+	fmt.Fprintf(&b, "legal %s\n", strings.Join(Legal(view, decision), " "))
+	fmt.Fprintf(&b, "go %s %d\n", decision, millis)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func joinCards(cards []card.Card) string {
+	names := make([]string, len(cards))
+	for i, c := range cards {
+		names[i] = c.String()
+	}
+	return strings.Join(names, " ")
+}
+
+func joinPlays(trick *engine.Trick) string {
+	plays := make([]string, len(trick.Cards))
+	for i, c := range trick.Cards {
+		plays[i] = fmt.Sprintf("%d:%s", trick.Seats[i], c)
+	}
+	return strings.Join(plays, " ")
+}
+
+// ParseAnswer reads the bot's answer to a decision, checking it is legal for
+// the view's seat.
+func ParseAnswer(line string, view *engine.PlayerView, decision Decision) (engine.Action, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 || fields[0] != string(decision) {
+		return engine.Action{}, fmt.Errorf("'%s' does not answer '%s'", line, decision)
+	}
+
+	switch decision {
+	case Bid:
+		bid, err := strconv.Atoi(fields[1])
+		if err != nil || bid < 0 || bid > len(view.Hand) {
+			return engine.Action{}, fmt.Errorf("'%s' is not a prediction between 0 and %d", fields[1], len(view.Hand))
+		}
+		return engine.BidOf(bid), nil
+	case Play:
+		c, err := card.Parse(fields[1])
+		if err != nil {
+			return engine.Action{}, err
+		}
+		if !slices.Contains(view.Hand, c) || !view.Trick.CanPlay(view.Hand, c) {
+			return engine.Action{}, fmt.Errorf("%s may not be played", c)
+		}
+		return engine.PlayOf(c), nil
+	}
+	symbol, err := card.ParseSymbol(fields[1])
+	if err != nil {
+		return engine.Action{}, err
+	}
+	return engine.TrumpOf(symbol), nil
+}
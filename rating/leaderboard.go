@@ -0,0 +1,68 @@
+package rating
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Leaderboard lists the entries of kind, every kind if "", best first by
+// their conservative rating.
+func (b *Book) Leaderboard(kind Kind) []*Entry {
+	var entries []*Entry
+	for _, e := range b.Entries {
+		if kind == "" || e.Kind == kind {
+			entries = append(entries, e)
+		}
+	}
+	slices.SortFunc(entries, func(a, b *Entry) int {
+		if a.Low() != b.Low() {
+			if a.Low() > b.Low() {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return entries
+}
+
+// WriteLeaderboard writes the leaderboard for the console: every entry with
+// its rating, the bounds its strength lies within and how it moved over its
+// last games.
+func WriteLeaderboard(w io.Writer, entries []*Entry, last int) error {
+	width := len("name")
+	for _, e := range entries {
+		width = max(width, len(e.Name))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%4s  %-*s %-6s %6s %7s %7s %7s  %s\n", "", width, "name", "kind", "games", "rating", "low", "high", "trend")
+	for place, e := range entries {
+		fmt.Fprintf(&b, "%4d  %-*s %-6s %6d %7.2f %7.2f %7.2f  %s\n", place+1, width, e.Name, e.Kind, e.Games, e.Mu, e.Low(), e.High(), trend(e, last))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// trend is how the rating moved over the last games of the entry.
+func trend(e *Entry, last int) string {
+	if last <= 0 || len(e.History) < 2 {
+		return ""
+	}
+	from := e.History[max(0, len(e.History)-last-1)]
+	return fmt.Sprintf("%+.2f over %d games", e.Mu-from.Mu, min(last, len(e.History)-1))
+}
+
+// WriteHistory writes how the rating of an entry went, game after game.
+func WriteHistory(w io.Writer, e *Entry) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s), %d games\n", e.Name, e.Kind, e.Games)
+	fmt.Fprintf(&b, "%6s  %-16s %6s %5s %7s %7s %7s\n", "game", "played", "score", "rank", "rating", "low", "high")
+	for _, p := range e.History {
+		fmt.Fprintf(&b, "%6d  %-16s %6d %5d %7.2f %7.2f %7.2f\n", p.Game, p.Played.Format("2006-01-02 15:04"), p.Score, p.Rank, p.Mu, p.Low(), p.High())
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
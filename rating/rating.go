@@ -0,0 +1,266 @@
+// Package rating rates players and agent configurations on the games they
+// played, with TrueSkill: every rating is a belief on the strength, a mean
+// and the uncertainty around it, so that beating a strong table counts for
+// more than beating a weak one. A game of several players is taken as the
+// duels of every pair of seats, each decided by their final scores.
+package rating
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"slices"
+	"time"
+)
+
+// The TrueSkill parameters, on the usual scale of a new player at 25 ± 25/3.
+const (
+	InitialMu    = 25.0
+	InitialSigma = InitialMu / 3
+	// beta is the spread of the performance in a game around the strength
+	beta = InitialSigma / 2
+	// tau is the drift of the strength from one game to the next, keeping
+	// the uncertainty from vanishing
+	tau = InitialSigma / 100
+	// drawChance is how often two seats end on the same score
+	drawChance = 0.05
+)
+
+type Kind string
+
+const (
+	Player Kind = "player" // a human, by name
+	Agent  Kind = "agent"  // an AI configuration, as in "hard/aggressive"
+)
+
+// Rating is the belief on a strength: Mu is the mean, Sigma the standard
+// deviation around it.
+type Rating struct {
+	Mu    float64 `json:"mu"`
+	Sigma float64 `json:"sigma"`
+}
+
+// Low and High bound the strength within three standard deviations. Low is
+// the conservative rating the leaderboard ranks by.
+func (r Rating) Low() float64 {
+	return r.Mu - 3*r.Sigma
+}
+
+func (r Rating) High() float64 {
+	return r.Mu + 3*r.Sigma
+}
+
+// Entry is the rating of a player or an agent configuration, and how it went
+// after every game.
+type Entry struct {
+	Name string `json:"name"`
+	Kind Kind   `json:"kind"`
+	Rating
+	Games   int     `json:"games"`
+	History []Point `json:"history"`
+}
+
+// Point is a rating as it stood after a game.
+type Point struct {
+	Game   int       `json:"game"` // the game of the book, from 1
+	Played time.Time `json:"played"`
+	Score  int       `json:"score"`
+	Rank   int       `json:"rank"`
+	Rating
+}
+
+// Seat is who played a seat of a game.
+type Seat struct {
+	Name string
+	Kind Kind
+}
+
+// Book holds the ratings and lives in a JSON file.
+type Book struct {
+	Games   int               `json:"games"`
+	Entries map[string]*Entry `json:"entries"`
+}
+
+func NewBook() *Book {
+	return &Book{Entries: make(map[string]*Entry)}
+}
+
+// Load reads the ratings from path; a missing file is an empty book.
+func Load(path string) (*Book, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewBook(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	book := NewBook()
+	if err := json.Unmarshal(data, book); err != nil {
+		return nil, err
+	}
+	if book.Entries == nil {
+		book.Entries = make(map[string]*Entry)
+	}
+	return book, nil
+}
+
+func (b *Book) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func key(seat Seat) string {
+	return string(seat.Kind) + ":" + seat.Name
+}
+
+// Entry returns the rating of who played seat, nil when unrated.
+func (b *Book) Entry(seat Seat) *Entry {
+	return b.Entries[key(seat)]
+}
+
+func (b *Book) entry(seat Seat) *Entry {
+	e := b.Entries[key(seat)]
+	if e == nil {
+		e = &Entry{Name: seat.Name, Kind: seat.Kind, Rating: Rating{InitialMu, InitialSigma}}
+		b.Entries[key(seat)] = e
+	}
+	return e
+}
+
+// Record rates a game from the final score of every seat. Every seat duels
+// every other, and its rating moves by the average of its duels. An entry
+// holding several seats, as an agent configuration playing itself, moves by
+// the average of its seats and doesn't duel itself.
+func (b *Book) Record(seats []Seat, scores []int) {
+	entries := make([]*Entry, len(seats))
+	before := make([]Rating, len(seats))
+	for i, seat := range seats {
+		entries[i] = b.entry(seat)
+		before[i] = entries[i].Rating
+		before[i].Sigma = math.Hypot(before[i].Sigma, tau)
+	}
+
+	// What every seat's duels move its mean by and shrink its variance by
+	moves := make([]float64, len(seats))
+	shrinks := make([]float64, len(seats))
+	for i := range seats {
+		duels := 0
+		for j := range seats {
+			if entries[i] == entries[j] {
+				continue
+			}
+			move, shrink := duel(before[i], before[j], scores[i]-scores[j])
+			moves[i] += move
+			shrinks[i] += shrink
+			duels++
+		}
+		if duels > 0 {
+			moves[i] /= float64(duels)
+			shrinks[i] /= float64(duels)
+		}
+	}
+
+	b.Games++
+	played := time.Now()
+	for i, e := range entries {
+		if i != slices.Index(entries, e) {
+			continue
+		}
+		// The history keeps the score and rank of the best of its seats
+		move, shrink, count := 0.0, 0.0, 0
+		score, rank := math.MinInt, 0
+		for j := range entries {
+			if entries[j] == e {
+				move += moves[j]
+				shrink += shrinks[j]
+				count++
+				score = max(score, scores[j])
+			}
+		}
+		for j := range scores {
+			if scores[j] > score {
+				rank++
+			}
+		}
+		move /= float64(count)
+		shrink /= float64(count)
+
+		variance := before[i].Sigma * before[i].Sigma
+		e.Mu = before[i].Mu + variance*move
+		e.Sigma = math.Sqrt(variance * math.Max(1-variance*shrink, 1e-4))
+		e.Games++
+		e.History = append(e.History, Point{Game: b.Games, Played: played, Score: score, Rank: rank + 1, Rating: e.Rating})
+	}
+}
+
+// duel is the TrueSkill update of a against b, who finished margin points
+// ahead of b: what a's mean moves by and its variance shrinks by, both per
+// unit of its variance.
+func duel(a, b Rating, margin int) (move, shrink float64) {
+	c := math.Sqrt(2*beta*beta + a.Sigma*a.Sigma + b.Sigma*b.Sigma)
+	t := (a.Mu - b.Mu) / c
+	epsilon := drawMargin() / c
+
+	var v, w float64
+	switch {
+	case margin > 0:
+		v, w = vWin(t, epsilon)
+	case margin < 0:
+		v, w = vWin(-t, epsilon)
+		v = -v
+	default:
+		v, w = vDraw(t, epsilon)
+	}
+	return v / c, w / (c * c)
+}
+
+// drawMargin is the difference of performance under which a duel is drawn,
+// as often as drawChance.
+func drawMargin() float64 {
+	return quantile((drawChance+1)/2) * math.Sqrt2 * beta
+}
+
+// vWin and vDraw are the corrections of the mean, and of the variance, by a
+// won or a drawn duel, the performance difference t being known to exceed
+// epsilon, or to lie within it.
+func vWin(t, epsilon float64) (v, w float64) {
+	x := t - epsilon
+	denominator := cdf(x)
+	if denominator < 1e-12 {
+		// Winning as the far weaker seat: the limit of the correction
+		return -x, 1
+	}
+	v = pdf(x) / denominator
+	return v, v * (v + x)
+}
+
+func vDraw(t, epsilon float64) (v, w float64) {
+	denominator := cdf(epsilon-t) - cdf(-epsilon-t)
+	if denominator < 1e-12 {
+		if t < 0 {
+			return -t - epsilon, 1
+		}
+		return -t + epsilon, 1
+	}
+	v = (pdf(-epsilon-t) - pdf(epsilon-t)) / denominator
+	w = v*v + ((epsilon-t)*pdf(epsilon-t)+(epsilon+t)*pdf(epsilon+t))/denominator
+	return v, w
+}
+
+func pdf(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
+
+func cdf(x float64) float64 {
+	return math.Erfc(-x/math.Sqrt2) / 2
+}
+
+func quantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
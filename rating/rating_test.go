@@ -0,0 +1,62 @@
+package rating_test
+
+import (
+	"math"
+	"testing"
+	"wizard/rating"
+)
+
+// TestRatings checks the ratings move the way the games went and follow the
+// strength of the table
+func TestRatings(t *testing.T) {
+	seat := func(name string) rating.Seat { return rating.Seat{Name: name, Kind: rating.Player} }
+	book := rating.NewBook()
+	book.Record([]rating.Seat{seat("A"), seat("B"), seat("C")}, []int{100, 50, 0})
+	a, b, c := book.Entry(seat("A")), book.Entry(seat("B")), book.Entry(seat("C"))
+	if !(a.Mu > b.Mu && b.Mu > c.Mu) || math.Abs(b.Mu-rating.InitialMu) > 1e-9 || math.Abs(a.Mu+c.Mu-2*rating.InitialMu) > 1e-9 {
+		t.Errorf("ratings %.2f, %.2f and %.2f after a game from the same start", a.Mu, b.Mu, c.Mu)
+	}
+	for _, e := range []*rating.Entry{a, b, c} {
+		if e.Sigma >= rating.InitialSigma || e.Games != 1 || len(e.History) != 1 {
+			t.Errorf("%s is rated %+v", e.Name, e)
+		}
+	}
+	if a.History[0].Rank != 1 || c.History[0].Rank != 3 || c.History[0].Score != 0 {
+		t.Errorf("the history keeps ranks %d and %d", a.History[0].Rank, c.History[0].Rank)
+	}
+
+	// Beating the stronger player counts for more than beating the weaker one
+	upset, expected := rating.NewBook(), rating.NewBook()
+	for _, book := range []*rating.Book{upset, expected} {
+		for range 5 {
+			book.Record([]rating.Seat{seat("strong"), seat("weak"), seat("new")}, []int{100, 0, 50})
+		}
+	}
+	upset.Record([]rating.Seat{seat("strong"), seat("new")}, []int{0, 10})
+	expected.Record([]rating.Seat{seat("weak"), seat("new")}, []int{0, 10})
+	gained := upset.Entry(seat("new")).Mu - expected.Entry(seat("new")).Mu
+	if gained <= 0 {
+		t.Errorf("beating the stronger player gave %.2f points more", gained)
+	}
+	if strong, weak := upset.Entry(seat("strong")), upset.Entry(seat("weak")); strong.Low() <= weak.Low() || upset.Leaderboard("")[2] != weak {
+		t.Errorf("the leaderboard reads %v", upset.Leaderboard(""))
+	}
+
+	// A draw between equals moves no one; an agent seated twice plays once
+	book.Record([]rating.Seat{seat("D"), seat("E"), seat("D")}, []int{30, 30, 30})
+	if d := book.Entry(seat("D")); math.Abs(d.Mu-rating.InitialMu) > 1e-9 || d.Games != 1 || len(d.History) != 1 {
+		t.Errorf("a draw of new players rated D %+v", d)
+	}
+
+	path := t.TempDir() + "/ratings.json"
+	if err := book.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := rating.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := loaded.Entry(seat("A")); loaded.Games != book.Games || e == nil || e.Mu != a.Mu || e.Sigma != a.Sigma || len(e.History) != 1 {
+		t.Errorf("loaded %d games, A as %+v", loaded.Games, e)
+	}
+}
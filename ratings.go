@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"wizard/rating"
+)
+
+// runRatings prints the leaderboard of the ratings, or the history of a
+// player or an AI configuration.
+func runRatings(args []string) {
+	flags := flag.NewFlagSet("ratings", flag.ExitOnError)
+	path := flags.String("ratings", "ratings.json", "file keeping the ratings")
+	kind := flags.String("kind", "", "list only the human players (player) or the AI configurations (agent)")
+	last := flags.Int("last", 10, "games the trend of every rating is taken over")
+	history := flags.String("history", "", "player or AI configuration, as in hard/aggressive, to show the rating history of")
+	flags.Parse(args)
+
+	if *kind != "" && *kind != string(rating.Player) && *kind != string(rating.Agent) {
+		fmt.Fprintf(os.Stderr, "unknown kind %q: %s or %s\n", *kind, rating.Player, rating.Agent)
+		os.Exit(2)
+	}
+	book, err := rating.Load(*path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *history != "" {
+		for _, k := range []rating.Kind{rating.Player, rating.Agent} {
+			if e := book.Entry(rating.Seat{Name: *history, Kind: k}); e != nil && (*kind == "" || k == rating.Kind(*kind)) {
+				rating.WriteHistory(os.Stdout, e)
+				return
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%s is not rated in %s\n", *history, *path)
+		os.Exit(1)
+	}
+
+	entries := book.Leaderboard(rating.Kind(*kind))
+	if len(entries) == 0 {
+		fmt.Printf("No one is rated in %s yet\n", *path)
+		return
+	}
+	fmt.Printf("Ratings after %d games, the strength within low and high, ranked by low\n\n", book.Games)
+	rating.WriteLeaderboard(os.Stdout, entries, *last)
+}
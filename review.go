@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"wizard/ai"
+	"wizard/gamelog"
+	"wizard/review"
+)
+
+// runReview goes over a logged game and reports the decisions that lost
+// expected score.
+func runReview(args []string) {
+	flags := flag.NewFlagSet("review", flag.ExitOnError)
+	log := flags.String("log", "games.jsonl", "game log to read the game from")
+	game := flags.Int("game", 0, "game of the log to review, counting from 1; 0 for the last")
+	all := flags.Bool("all", false, "review the computer players too")
+	deals := flags.Int("deals", 200, "deals sampled to evaluate every decision")
+	budget := flags.Duration("budget", 0, "time the evaluation may take per decision, 0 for no limit")
+	threshold := flags.Float64("threshold", 5, "expected points a decision must lose to count as a mistake")
+	seed := flags.Int64("seed", 1, "seed of the sampled deals")
+	html := flags.String("html", "", "file the report is also written to as a web page")
+	flags.Parse(args)
+
+	logged, err := gamelog.Read(*log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(logged) == 0 {
+		fmt.Fprintf(os.Stderr, "no game logged in %s: play with -log %s first\n", *log, *log)
+		os.Exit(1)
+	}
+	number := *game
+	if number == 0 {
+		number = len(logged)
+	}
+	if number < 1 || number > len(logged) {
+		fmt.Fprintf(os.Stderr, "%s has games 1 to %d\n", *log, len(logged))
+		os.Exit(2)
+	}
+
+	report, err := review.Analyse(logged[number-1], review.Options{
+		Search:    ai.Search{Iterations: *deals, Budget: *budget},
+		Seed:      *seed,
+		Threshold: *threshold,
+		All:       *all,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "game %d: %v\n", number, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Review of game %d of %s\n\n", number, *log)
+	report.WriteText(os.Stdout)
+
+	if *html != "" {
+		file, err := os.Create(*html)
+		if err == nil {
+			err = report.WriteHTML(file)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("\nWritten to %s\n", *html)
+	}
+}
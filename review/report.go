@@ -0,0 +1,137 @@
+package review
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// top is how many of the worst mistakes the report lists.
+const top = 10
+
+// WriteText writes the report for the console: what every reviewed player
+// lost, the turns that cost them and the worst mistakes of the game.
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("Expected points lost\n")
+	fmt.Fprintf(&b, "%-16s %10s %10s %10s\n", "player", "decisions", "mistakes", "lost")
+	for seat, name := range r.Players {
+		if !r.Reviewed[seat] {
+			continue
+		}
+		fmt.Fprintf(&b, "%-16s %10d %10d %10.1f\n", name, r.Decisions[seat], r.mistakes(seat), r.Lost[seat])
+	}
+
+	b.WriteString("\nBy turn, with the worst mistake of each\n")
+	for turn, lost := range r.Turns {
+		for seat, cost := range lost {
+			m, ok := r.Worst(turn+1, seat)
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&b, "turn %2d  %-16s %6.1f  %s instead of %s (%.1f)\n", turn+1, r.Players[seat], cost, m.Decision, m.Best, m.Cost)
+		}
+	}
+
+	b.WriteString("\nWorst mistakes\n")
+	if len(r.Mistakes) == 0 {
+		b.WriteString("none\n")
+	}
+	for _, m := range r.Mistakes[:min(top, len(r.Mistakes))] {
+		fmt.Fprintf(&b, "%6.1f  turn %2d (%d cards)  %-16s %s instead of %s\n", m.Cost, m.Turn, m.HandSize, m.Player, m.Decision, m.Best)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mistakes counts the mistakes of seat.
+func (r *Report) mistakes(seat int) int {
+	count := 0
+	for _, m := range r.Mistakes {
+		if m.Seat == seat {
+			count++
+		}
+	}
+	return count
+}
+
+var page = template.Must(template.New("review").Funcs(template.FuncMap{
+	"points": func(x float64) string { return fmt.Sprintf("%.1f", x) },
+	"inc":    func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Game review</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+td.mistake { background: #fdd; }
+</style>
+</head>
+<body>
+<h1>Game review</h1>
+
+<h2>Expected points lost</h2>
+<table>
+<tr><th>player</th><th>decisions</th><th>mistakes</th><th>lost</th></tr>
+{{range .Players}}<tr><td>{{.Name}}</td><td>{{.Decisions}}</td><td>{{.Mistakes}}</td><td>{{points .Lost}}</td></tr>
+{{end}}</table>
+
+<h2>By turn</h2>
+<table>
+<tr><th>turn</th>{{range .Players}}<th>{{.Name}}</th>{{end}}</tr>
+{{range $turn, $row := .Turns}}<tr><td>{{inc $turn}}</td>{{range $row}}{{if .Worst}}<td class="mistake" title="{{.Worst.Decision}} instead of {{.Worst.Best}} ({{points .Worst.Cost}})">{{points .Lost}}</td>{{else}}<td>{{points .Lost}}</td>{{end}}{{end}}</tr>
+{{end}}</table>
+
+<h2>Worst mistakes</h2>
+{{if .Mistakes}}<table>
+<tr><th>player</th><th>turn</th><th>cards</th><th>decision</th><th>best</th><th>cost</th></tr>
+{{range .Mistakes}}<tr><td>{{.Player}}</td><td>{{.Turn}}</td><td>{{.HandSize}}</td><td>{{.Decision}}</td><td>{{.Best}}</td><td>{{points .Cost}}</td></tr>
+{{end}}</table>{{else}}<p>None.</p>{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes the report as a web page, with a table of the points lost
+// by every player in every turn; hovering a marked cell tells the worst
+// mistake of it.
+func (r *Report) WriteHTML(w io.Writer) error {
+	type player struct {
+		Name      string
+		Decisions int
+		Mistakes  int
+		Lost      float64
+	}
+	type cell struct {
+		Lost  float64
+		Worst *Mistake
+	}
+	data := struct {
+		Players  []player
+		Turns    [][]cell
+		Mistakes []Mistake
+	}{Mistakes: r.Mistakes[:min(top, len(r.Mistakes))]}
+
+	var seats []int
+	for seat, name := range r.Players {
+		if r.Reviewed[seat] {
+			seats = append(seats, seat)
+			data.Players = append(data.Players, player{name, r.Decisions[seat], r.mistakes(seat), r.Lost[seat]})
+		}
+	}
+	for turn, lost := range r.Turns {
+		row := make([]cell, len(seats))
+		for i, seat := range seats {
+			row[i].Lost = lost[seat]
+			if m, ok := r.Worst(turn+1, seat); ok {
+				row[i].Worst = &m
+			}
+		}
+		data.Turns = append(data.Turns, row)
+	}
+	return page.Execute(w, data)
+}
@@ -0,0 +1,124 @@
+// Package review goes over a logged game after the fact: every decision of
+// the seats under review is replayed through the expert's evaluation, and
+// the ones that lost expected score are marked with what they cost.
+package review
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"wizard/ai"
+	"wizard/gamelog"
+)
+
+// Options sets what is reviewed and how hard the evaluator looks.
+type Options struct {
+	Search ai.Search // the deals sampled per decision
+	Seed   int64
+	// the expected points a decision must lose to count as a mistake, so that
+	// the noise of the sampling is not taken for one
+	Threshold float64
+	// review the AI seats too, not just the human ones
+	All bool
+}
+
+// Mistake is a decision that lost expected score.
+type Mistake struct {
+	Turn     int // counting from 1
+	HandSize int
+	Seat     int
+	Player   string
+	Decision string  // as in "bid 3", "play W" or "trump Elf"
+	Best     string  // the decision the evaluator prefers
+	Cost     float64 // expected points lost
+}
+
+// Report is the outcome of a review.
+type Report struct {
+	Players  []string
+	Reviewed []bool // the seats under review
+	// the decisions reviewed and the expected points lost, per seat
+	Decisions []int
+	Lost      []float64
+	// the expected points lost per turn and seat, mistakes or not
+	Turns [][]float64
+	// worst first
+	Mistakes []Mistake
+}
+
+// Analyse reviews a logged game. Only the human seats are reviewed unless
+// opts.All is set; a log that does not tell the agents has every seat taken
+// as human.
+func Analyse(game gamelog.Game, opts Options) (*Report, error) {
+	n := len(game.Players)
+	r := &Report{
+		Players:   game.Players,
+		Reviewed:  make([]bool, n),
+		Decisions: make([]int, n),
+		Lost:      make([]float64, n),
+	}
+	for seat := range n {
+		r.Reviewed[seat] = opts.All || seat >= len(game.Agents) || game.Agents[seat] == ""
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	err := game.Replay(func(d gamelog.Decision) {
+		for len(r.Turns) <= d.Turn {
+			r.Turns = append(r.Turns, make([]float64, n))
+		}
+		seat := d.State.ToAct
+		if seat >= n || !r.Reviewed[seat] {
+			return
+		}
+
+		view := d.State.View(seat)
+		actions, scores := ai.Evaluate(&view, opts.Search, rng.Int63())
+		if len(actions) < 2 {
+			return
+		}
+		best, taken := 0, -1
+		for i, action := range actions {
+			if scores[i] > scores[best] {
+				best = i
+			}
+			if action == d.Action && taken < 0 {
+				taken = i
+			}
+		}
+		if taken < 0 {
+			return
+		}
+
+		cost := scores[best] - scores[taken]
+		r.Decisions[seat]++
+		r.Lost[seat] += cost
+		r.Turns[d.Turn][seat] += cost
+		if cost >= opts.Threshold && cost > 0 {
+			r.Mistakes = append(r.Mistakes, Mistake{
+				Turn:     d.Turn + 1,
+				HandSize: d.State.HandSize,
+				Seat:     seat,
+				Player:   game.Players[seat],
+				Decision: ai.Describe(d.Action),
+				Best:     ai.Describe(actions[best]),
+				Cost:     cost,
+			})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(r.Mistakes, func(a, b Mistake) int { return cmp.Compare(b.Cost, a.Cost) })
+	return r, nil
+}
+
+// Worst returns the costliest mistake of seat in turn, counting from 1.
+func (r *Report) Worst(turn, seat int) (Mistake, bool) {
+	for _, m := range r.Mistakes {
+		if m.Turn == turn && m.Seat == seat {
+			return m, true
+		}
+	}
+	return Mistake{}, false
+}
@@ -0,0 +1,54 @@
+package review_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/engine"
+	"wizard/gamelog"
+	"wizard/review"
+)
+
+// TestReview reviews a logged game where a random player sits at the table
+func TestReview(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	agents := []ai.Agent{ai.New(ai.Easy, ai.Balanced, 1), ai.New(ai.Medium, ai.Balanced, 2), ai.New(ai.Medium, ai.Balanced, 3)}
+	game := gamelog.Game{Players: []string{"Random", "B", "C"}, Agents: []string{"", "medium", "medium"}}
+	for handSize := 1; handSize <= 4; handSize++ {
+		arena.Turn(agents, handSize%3, handSize, rng, func(e engine.Event) { game.Events = append(game.Events, e) })
+	}
+
+	decisions := 0
+	if err := game.Replay(func(gamelog.Decision) { decisions++ }); err != nil {
+		t.Fatal(err)
+	}
+	if decisions < 3*(1+2+3+4)+3*4 {
+		t.Errorf("replayed %d decisions of 4 turns", decisions)
+	}
+
+	report, err := review.Analyse(game, review.Options{Search: ai.Search{Iterations: 30}, Seed: 1, Threshold: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Reviewed[0] || report.Reviewed[1] || report.Decisions[0] == 0 || report.Decisions[1] != 0 {
+		t.Errorf("reviewed %v with %v decisions", report.Reviewed, report.Decisions)
+	}
+	if report.Lost[0] <= 0 {
+		t.Error("a random player lost no expected points")
+	}
+	for i, m := range report.Mistakes {
+		if m.Seat != 0 || m.Cost < 1 || i > 0 && m.Cost > report.Mistakes[i-1].Cost {
+			t.Errorf("mistake %+v out of place", m)
+		}
+	}
+
+	var text, page strings.Builder
+	if err := report.WriteText(&text); err != nil || !strings.Contains(text.String(), "Random") {
+		t.Errorf("text report %q: %v", text.String(), err)
+	}
+	if err := report.WriteHTML(&page); err != nil || !strings.Contains(page.String(), "<table>") {
+		t.Errorf("html report: %v", err)
+	}
+}
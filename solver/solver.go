@@ -0,0 +1,449 @@
+// Package solver plays out a turn whose hands are all known, for analysis
+// and testing. It follows the engine's trick rules, so it agrees with the
+// game on every trick.
+package solver
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"wizard/card"
+	"wizard/engine"
+)
+
+// Bounds are the tricks a seat takes in the turn, those already won
+// included, when it plays its best and the other seats play against it.
+type Bounds struct {
+	Min int // the seat tries to lose every trick, the others to feed it
+	Max int // the seat tries to win every trick, the others to stop it
+}
+
+// Solve computes the bounds of every seat from the position of state. The
+// predictions play no part in the tricks, so a state still in the bidding
+// is solved from the first lead. The searches run in parallel.
+func Solve(state *engine.State) []Bounds {
+	bounds := make([]Bounds, state.NumPlayers())
+	var wg sync.WaitGroup
+	for seat := range bounds {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			bounds[seat].Min = Tricks(state, seat, false)
+		}()
+		go func() {
+			defer wg.Done()
+			bounds[seat].Max = Tricks(state, seat, true)
+		}()
+	}
+	wg.Wait()
+	return bounds
+}
+
+// Tricks computes the tricks seat takes in the turn when it tries to win as
+// many as it can (most) or as few, with every other seat working against it.
+func Tricks(state *engine.State, seat int, most bool) int {
+	s := newSearch(state, seat, most)
+
+	// Null window searches, one more trick at a time, share what they
+	// learn through the transposition table
+	tricks := 0
+	for tricks < s.tricksLeft() && s.search(tricks, tricks+1) > tricks {
+		tricks++
+	}
+	return state.Won[seat] + tricks
+}
+
+// Score computes the points seat makes in the turn when it plays for its
+// bid and every other seat plays to make it miss. The state must be in the
+// play, with every bid known.
+func Score(state *engine.State, seat int) int {
+	s := newSearch(state, seat, state.Won[seat] < state.Bids[seat])
+	s.scores = make(map[scorePosition]entry)
+	return s.score(state.Bids[seat], state.Won[seat], math.MinInt, math.MaxInt)
+}
+
+// search is one alpha-beta search of the tricks target takes from a position.
+type search struct {
+	target int
+	most   bool
+	hands  [][]card.Card
+	trump  card.Symbol
+	trick  engine.Trick
+	toAct  int
+	table  map[position]entry
+	scores map[scorePosition]entry // the points of Score, by position
+}
+
+// scorePosition is the start of a trick for Score, where the points depend
+// on the tricks target has won so far.
+type scorePosition struct {
+	position
+	won int
+}
+
+// position identifies the start of a trick: who leads and, for every
+// symbol, which seat holds each card still in play, from the lowest up. Only
+// the order of the cards matters to the tricks, not their numbers, so
+// positions where different cards are gone but the same order is left
+// share their result.
+type position struct {
+	suits   [4]uint64
+	special [6]uint8 // Wizards and Jokers held by each seat
+	leader  int
+}
+
+// entry bounds the tricks target takes from a position.
+type entry struct {
+	lower, upper int
+}
+
+func newSearch(state *engine.State, seat int, most bool) *search {
+	s := &search{
+		target: seat,
+		most:   most,
+		hands:  make([][]card.Card, state.NumPlayers()),
+		trump:  state.TrumpSuit,
+		trick:  state.Trick.Clone(),
+		toAct:  state.ToAct,
+		table:  make(map[position]entry),
+	}
+	for i, hand := range state.Hands {
+		s.hands[i] = slices.Clone(hand)
+	}
+	if state.Phase == engine.Bidding || state.Phase == engine.Choosing {
+		s.toAct = (state.Dealer + 1) % state.NumPlayers()
+	}
+	return s
+}
+
+// search returns the tricks target takes from here, exactly when the result
+// lies strictly between alpha and beta, otherwise a bound on the side of the
+// window it falls.
+func (s *search) search(alpha, beta int) int {
+	if len(s.hands[s.toAct]) == 0 || beta <= 0 {
+		return 0
+	}
+	if left := s.tricksLeft(); alpha >= left {
+		return left
+	}
+
+	var key position
+	leading := len(s.trick.Cards) == 0
+	if leading {
+		key = s.position()
+		if e, ok := s.table[key]; ok {
+			if e.lower >= beta || e.lower == e.upper {
+				return e.lower
+			}
+			if e.upper <= alpha {
+				return e.upper
+			}
+			alpha, beta = max(alpha, e.lower), min(beta, e.upper)
+		}
+		if lower, upper := s.wizardBounds(); lower >= beta {
+			return lower
+		} else if upper <= alpha {
+			return upper
+		}
+	}
+	alpha0, beta0 := alpha, beta
+
+	maximizing := (s.toAct == s.target) == s.most
+	best := -1
+	if !maximizing {
+		best = len(s.hands[s.toAct]) + 1
+	}
+
+	for _, c := range s.moves(maximizing) {
+		trick, toAct := s.trick, s.toAct
+		won := s.play(c)
+		value := won + s.search(alpha-won, beta-won)
+		s.undo(c, trick, toAct)
+
+		if maximizing {
+			best = max(best, value)
+			alpha = max(alpha, value)
+		} else {
+			best = min(best, value)
+			beta = min(beta, value)
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	if leading {
+		e := entry{lower: 0, upper: len(s.hands[s.toAct])}
+		if old, ok := s.table[key]; ok {
+			e = old
+		}
+		switch {
+		case best <= alpha0:
+			e.upper = min(e.upper, best)
+		case best >= beta0:
+			e.lower = max(e.lower, best)
+		default:
+			e.lower, e.upper = best, best
+		}
+		s.table[key] = e
+	}
+	return best
+}
+
+// score is the alpha-beta search of Score: target, having won tricks so far,
+// maximizes its points and the other seats minimize them.
+func (s *search) score(bid, won, alpha, beta int) int {
+	if len(s.hands[s.toAct]) == 0 {
+		return engine.Score(bid, won)
+	}
+	if won > bid {
+		// every trick more costs, and the best left is to take none
+		if best := engine.Score(bid, won); best <= alpha {
+			return best
+		}
+	}
+
+	var key scorePosition
+	leading := len(s.trick.Cards) == 0
+	if leading {
+		key = scorePosition{s.position(), won}
+		if e, ok := s.scores[key]; ok {
+			if e.lower >= beta || e.lower == e.upper {
+				return e.lower
+			}
+			if e.upper <= alpha {
+				return e.upper
+			}
+			alpha, beta = max(alpha, e.lower), min(beta, e.upper)
+		}
+	}
+	alpha0, beta0 := alpha, beta
+
+	// target plays for tricks while its bid needs them, the others against it
+	s.most = won < bid
+	maximizing := s.toAct == s.target
+	best := math.MaxInt
+	if maximizing {
+		best = math.MinInt
+	}
+	for _, c := range s.moves(maximizing == s.most) {
+		trick, toAct := s.trick, s.toAct
+		value := s.score(bid, won+s.play(c), alpha, beta)
+		s.undo(c, trick, toAct)
+
+		if maximizing {
+			best = max(best, value)
+			alpha = max(alpha, value)
+		} else {
+			best = min(best, value)
+			beta = min(beta, value)
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	if leading {
+		e := entry{lower: math.MinInt, upper: math.MaxInt}
+		if old, ok := s.scores[key]; ok {
+			e = old
+		}
+		switch {
+		case best <= alpha0:
+			e.upper = min(e.upper, best)
+		case best >= beta0:
+			e.lower = max(e.lower, best)
+		default:
+			e.lower, e.upper = best, best
+		}
+		s.scores[key] = e
+	}
+	return best
+}
+
+// moves returns the distinct legal cards of the seat to act, the likeliest
+// best first.
+func (s *search) moves(maximizing bool) []card.Card {
+	var moves []card.Card
+	for _, c := range s.trick.LegalPlays(s.hands[s.toAct]) {
+		if !slices.Contains(moves, c) && !s.equivalent(c, moves) {
+			moves = append(moves, c)
+		}
+	}
+
+	// The side wanting target to take the trick: target itself when it
+	// plays for the most tricks, the others when it plays for the fewest
+	feed := (s.toAct == s.target) == maximizing
+	winning := s.trick.Winner() == s.target
+	slices.SortStableFunc(moves, func(a, b card.Card) int {
+		return s.order(b, feed, winning) - s.order(a, feed, winning)
+	})
+	return moves
+}
+
+// equivalent reports whether a card of moves plays just like c: both are of
+// the same symbol and no card between them is left in any hand or on the
+// trick, so no card can tell them apart.
+func (s *search) equivalent(c card.Card, moves []card.Card) bool {
+	if c.IsWizard || c.IsJoker {
+		return false
+	}
+	for _, m := range moves {
+		if m.IsWizard || m.IsJoker || m.Symbol != c.Symbol {
+			continue
+		}
+		low, high := min(m.Number, c.Number), max(m.Number, c.Number)
+		if !s.live(c.Symbol, low, high) {
+			return true
+		}
+	}
+	return false
+}
+
+// live reports whether a card of symbol numbered strictly between low and
+// high is still in a hand or on the trick.
+func (s *search) live(symbol card.Symbol, low, high int) bool {
+	between := func(c card.Card) bool {
+		return !c.IsWizard && !c.IsJoker && c.Symbol == symbol && c.Number > low && c.Number < high
+	}
+	for _, hand := range s.hands {
+		if slices.ContainsFunc(hand, between) {
+			return true
+		}
+	}
+	return slices.ContainsFunc(s.trick.Cards, between)
+}
+
+// wizardBounds bounds the tricks target takes from the start of a trick by
+// the Wizards: each of its Wizards wins unless an opponent's was played
+// before it, and, when the opponents play against it, they can spread their
+// Wizards and take a trick with each one target can't top.
+func (s *search) wizardBounds() (lower, upper int) {
+	own, others := 0, 0
+	for seat, hand := range s.hands {
+		for _, c := range hand {
+			if !c.IsWizard {
+				continue
+			}
+			if seat == s.target {
+				own++
+			} else {
+				others++
+			}
+		}
+	}
+
+	left := s.tricksLeft()
+	lower, upper = max(0, own-others), left
+	if s.most {
+		upper = max(0, left-max(0, others-own))
+	}
+	return lower, upper
+}
+
+// tricksLeft counts the tricks still to be played, the current one included.
+func (s *search) tricksLeft() int {
+	if len(s.trick.Cards) > 0 {
+		return len(s.hands[s.trick.Seats[0]]) + 1
+	}
+	return len(s.hands[s.toAct])
+}
+
+// order scores a card for the move ordering. Target takes the trick as
+// cheaply as it can, or ducks with its highest loser; the other seats take
+// the trick from target as cheaply as they can, or else keep their strong
+// cards.
+func (s *search) order(c card.Card, feed, winning bool) int {
+	strength := rank(c, s.trump)
+	beats := s.trick.Beats(c)
+	switch {
+	case s.toAct == s.target && feed:
+		if beats {
+			return 100 - strength
+		}
+		return -strength
+	case s.toAct == s.target:
+		if beats {
+			return -strength
+		}
+		return 100 + strength
+	case feed:
+		// leave the trick to target, throwing high cards under it
+		if winning != beats {
+			return 100 + strength
+		}
+		return strength
+	}
+	if beats && (winning || len(s.trick.Cards) == 0) {
+		return 100 - strength
+	}
+	return -strength
+}
+
+// play puts c on the trick and returns 1 if that completed a trick target won.
+func (s *search) play(c card.Card) int {
+	hand := s.hands[s.toAct]
+	i := slices.Index(hand, c)
+	hand[i], hand[len(hand)-1] = hand[len(hand)-1], hand[i]
+	s.hands[s.toAct] = hand[:len(hand)-1]
+
+	// undo restores the trick as it was, so the cards can be appended
+	// over those of the tricks searched before
+	s.trick.Play(s.toAct, c)
+	if len(s.trick.Cards) < len(s.hands) {
+		s.toAct = (s.toAct + 1) % len(s.hands)
+		return 0
+	}
+
+	winner := s.trick.Winner()
+	s.trick = engine.NewTrick(s.trump)
+	s.toAct = winner
+	if winner == s.target {
+		return 1
+	}
+	return 0
+}
+
+func (s *search) undo(c card.Card, trick engine.Trick, toAct int) {
+	s.trick, s.toAct = trick, toAct
+	// play swapped c to the end of the hand: growing the slice brings it back
+	s.hands[toAct] = s.hands[toAct][:len(s.hands[toAct])+1]
+}
+
+func (s *search) position() position {
+	p := position{leader: s.toAct}
+	var owners [4][14]uint64
+	for seat, hand := range s.hands {
+		for _, c := range hand {
+			switch {
+			case c.IsWizard:
+				p.special[seat] += 8
+			case c.IsJoker:
+				p.special[seat]++
+			default:
+				owners[slices.Index(card.Symbols, c.Symbol)][c.Number] = uint64(seat + 1)
+			}
+		}
+	}
+	for suit := range owners {
+		for _, owner := range owners[suit] {
+			if owner != 0 {
+				p.suits[suit] = p.suits[suit]<<3 | owner
+			}
+		}
+	}
+	return p
+}
+
+// rank orders the cards by strength: Jokers, numbers, trumps, Wizards.
+func rank(c card.Card, trump card.Symbol) int {
+	switch {
+	case c.IsWizard:
+		return 30
+	case c.IsJoker:
+		return 0
+	case c.Symbol == trump && trump != "":
+		return c.Number + 13
+	}
+	return c.Number
+}
@@ -0,0 +1,101 @@
+package solver_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"wizard/deck"
+	"wizard/engine"
+	"wizard/solver"
+)
+
+// TestSolverMatchesEngine checks the double-dummy solver against a plain
+// minimax over the engine's own moves
+func TestSolverMatchesEngine(t *testing.T) {
+	for seed := int64(0); seed < 60; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		turnDeck := deck.InitDeck()
+		rng.Shuffle(len(turnDeck), func(i, j int) { turnDeck[i], turnDeck[j] = turnDeck[j], turnDeck[i] })
+		numPlayers := 3 + int(seed)%2
+		state := engine.NewState(numPlayers, int(seed)%numPlayers, 1+int(seed)%3, turnDeck)
+		for state.Phase != engine.Playing {
+			state.Apply(state.LegalActions()[0])
+		}
+
+		for seat, bounds := range solver.Solve(state) {
+			if low, high := minimax(state, seat, false), minimax(state, seat, true); bounds.Min != low || bounds.Max != high {
+				t.Fatalf("deal %d seat %d: solver %+v, minimax %d..%d", seed, seat, bounds, low, high)
+			}
+		}
+	}
+}
+
+// minimax returns the tricks seat takes when it plays for the most (or the
+// fewest) and every other seat plays against it
+func minimax(state *engine.State, seat int, most bool) int {
+	if state.Phase == engine.Done {
+		return state.Won[seat]
+	}
+	maximizing := (state.ToAct == seat) == most
+	best := -1
+	if !maximizing {
+		best = len(state.Hands[seat]) + state.Won[seat] + 1
+	}
+	for _, action := range state.LegalActions() {
+		next := state.Clone()
+		next.Apply(action)
+		tricks := minimax(next, seat, most)
+		if maximizing && tricks > best || !maximizing && tricks < best {
+			best = tricks
+		}
+	}
+	return best
+}
+
+// TestScoreMatchesEngine checks the solver's scores against a plain minimax
+// over the engine's own moves
+func TestScoreMatchesEngine(t *testing.T) {
+	for seed := int64(0); seed < 40; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		turnDeck := deck.InitDeck()
+		turnDeck.ShuffleWith(rng)
+		numPlayers := 3 + int(seed)%2
+		state := engine.NewState(numPlayers, int(seed)%numPlayers, 1+int(seed)%3, turnDeck)
+		for state.Phase != engine.Playing {
+			action := state.LegalActions()[0]
+			if state.Phase == engine.Bidding {
+				action = engine.BidOf(rng.Intn(state.HandSize + 1))
+			}
+			state.Apply(action)
+		}
+
+		for seat := range numPlayers {
+			if got, want := solver.Score(state, seat), minimaxScore(state, seat); got != want {
+				t.Fatalf("deal %d seat %d: solver %d, minimax %d", seed, seat, got, want)
+			}
+		}
+	}
+}
+
+// minimaxScore returns the points seat makes when it plays for its bid and
+// every other seat plays against it
+func minimaxScore(state *engine.State, seat int) int {
+	if state.Phase == engine.Done {
+		return engine.Score(state.Bids[seat], state.Won[seat])
+	}
+	maximizing := state.ToAct == seat
+	best := math.MaxInt
+	if maximizing {
+		best = math.MinInt
+	}
+	for _, action := range state.LegalActions() {
+		next := state.Clone()
+		next.Apply(action)
+		if value := minimaxScore(next, seat); maximizing {
+			best = max(best, value)
+		} else {
+			best = min(best, value)
+		}
+	}
+	return best
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"wizard/protocol"
+	"wizard/tournament"
+)
+
+// runTournament plays the entrants against each other and writes the
+// standings.
+func runTournament(args []string) {
+	flags := flag.NewFlagSet("tournament", flag.ExitOnError)
+	entrantList := flags.String("entrants", "", "semicolon separated entrants, each [name=]level[/profile] or [name=]bot:command")
+	format := flags.String("format", string(tournament.RoundRobin), "round-robin or swiss")
+	players := flags.Int("players", 4, "seats at every table")
+	rounds := flags.Int("rounds", 1, "times the round-robin schedule is played, or rounds of a Swiss tournament")
+	seed := flags.Int64("seed", 1, "seed of the deals, the players and the first Swiss round")
+	workers := flags.Int("workers", 0, "games played at once, 0 for one per CPU")
+	checkpoint := flags.String("checkpoint", "", "file the finished games are kept in to resume the tournament; without one an interrupted tournament starts over")
+	botTimeout := flags.Duration("bot-timeout", protocol.DefaultTimeout, "time a bot may think per decision before its seat falls back to the built-in AI")
+	quiet := flags.Bool("quiet", false, "don't report every game as it ends")
+	flags.Parse(args)
+
+	var entrants []tournament.Entrant
+	for _, spec := range strings.Split(*entrantList, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		entrant, err := tournament.ParseEntrant(spec)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		entrants = append(entrants, entrant)
+	}
+
+	start := time.Now()
+	config := tournament.Config{
+		Entrants:   entrants,
+		Format:     tournament.Format(*format),
+		Players:    *players,
+		Rounds:     *rounds,
+		Seed:       *seed,
+		Workers:    *workers,
+		Checkpoint: *checkpoint,
+		BotTimeout: *botTimeout,
+	}
+	if !*quiet {
+		config.Progress = func(g tournament.Game) {
+			var seats []string
+			for seat, entrant := range g.Seats {
+				seats = append(seats, fmt.Sprintf("%s %d", entrants[entrant].Name, g.Scores[seat]))
+			}
+			fmt.Printf("game %d, round %d: %s\n", g.Number+1, g.Round+1, strings.Join(seats, ", "))
+			for name, fault := range g.Faults {
+				fmt.Printf("  the bot of %s was stopped: %s\n", name, fault)
+			}
+		}
+	}
+	games, err := tournament.Run(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n%s tournament of %d games between %d entrants, %d seats a table, in %v\n\n", config.Format, len(games), len(entrants), *players, time.Since(start).Round(time.Second))
+	tournament.Standings(entrants, games).Write(os.Stdout)
+}
@@ -0,0 +1,161 @@
+package tournament
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Table is the standings of a tournament.
+type Table struct {
+	Entrants []Entrant
+	// Rows is the standings, best first: by win rate, then average score
+	Rows []Row
+	// HeadToHead[a][b] is how entrant a fared against entrant b in the games
+	// they played together
+	HeadToHead [][]Record
+}
+
+// Row is the standing of an entrant.
+type Row struct {
+	Entrant int
+	Games   int
+	Wins    int // games finished first, alone or on the same score as others
+	Points  int
+	Exact   int // bids made exactly
+	Bids    int
+	Faults  int // games its bot was stopped in
+}
+
+func (r Row) WinRate() float64 {
+	return ratio(r.Wins, r.Games)
+}
+
+func (r Row) AverageScore() float64 {
+	return ratio(r.Points, r.Games)
+}
+
+func (r Row) BidAccuracy() float64 {
+	return ratio(r.Exact, r.Bids)
+}
+
+// Record counts the games an entrant scored more, less or as much as another.
+type Record struct {
+	Wins, Losses, Draws int
+}
+
+func ratio(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
+
+// Standings adds the games up for every entrant.
+func Standings(entrants []Entrant, games []Game) Table {
+	table := Table{Entrants: entrants, Rows: make([]Row, len(entrants)), HeadToHead: make([][]Record, len(entrants))}
+	for i := range entrants {
+		table.Rows[i].Entrant = i
+		table.HeadToHead[i] = make([]Record, len(entrants))
+	}
+
+	for _, g := range games {
+		for seat, entrant := range g.Seats {
+			row := &table.Rows[entrant]
+			row.Games++
+			row.Points += g.Scores[seat]
+			row.Exact += g.Exact[seat]
+			row.Bids += g.Bids
+			if g.Ranks[seat] == 1 {
+				row.Wins++
+			}
+			for other, opponent := range g.Seats {
+				record := &table.HeadToHead[entrant][opponent]
+				switch {
+				case other == seat:
+				case g.Scores[seat] > g.Scores[other]:
+					record.Wins++
+				case g.Scores[seat] < g.Scores[other]:
+					record.Losses++
+				default:
+					record.Draws++
+				}
+			}
+		}
+		for _, entrant := range g.Seats {
+			if _, ok := g.Faults[entrants[entrant].Name]; ok {
+				table.Rows[entrant].Faults++
+			}
+		}
+	}
+
+	slices.SortStableFunc(table.Rows, func(a, b Row) int {
+		switch {
+		case a.WinRate() != b.WinRate():
+			return compare(b.WinRate(), a.WinRate())
+		case a.AverageScore() != b.AverageScore():
+			return compare(b.AverageScore(), a.AverageScore())
+		}
+		return a.Entrant - b.Entrant
+	})
+	return table
+}
+
+func compare(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	return 1
+}
+
+// Write writes the standings for the console, then the head-to-head results:
+// in every cell the games the row's entrant scored more, less and as much as
+// the column's.
+func (t Table) Write(w io.Writer) error {
+	width := len("entrant")
+	for _, e := range t.Entrants {
+		width = max(width, len(e.Name))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%4s  %-*s %6s %8s %10s %10s\n", "", width, "entrant", "games", "win rate", "avg score", "exact bids")
+	for place, row := range t.Rows {
+		fmt.Fprintf(&b, "%4d  %-*s %6d %7.1f%% %10.1f %9.1f%%", place+1, width, t.Entrants[row.Entrant].Name, row.Games, 100*row.WinRate(), row.AverageScore(), 100*row.BidAccuracy())
+		if row.Faults > 0 {
+			fmt.Fprintf(&b, "  (bot stopped in %d games)", row.Faults)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nHead to head, won-lost-drawn\n")
+	fmt.Fprintf(&b, "%-*s", width, "")
+	for _, row := range t.Rows {
+		fmt.Fprintf(&b, "  %11s", shorten(t.Entrants[row.Entrant].Name, 11))
+	}
+	b.WriteString("\n")
+	for _, row := range t.Rows {
+		fmt.Fprintf(&b, "%-*s", width, t.Entrants[row.Entrant].Name)
+		for _, column := range t.Rows {
+			record := t.HeadToHead[row.Entrant][column.Entrant]
+			switch {
+			case row.Entrant == column.Entrant:
+				fmt.Fprintf(&b, "  %11s", "-")
+			case record == Record{}:
+				fmt.Fprintf(&b, "  %11s", "")
+			default:
+				fmt.Fprintf(&b, "  %11s", fmt.Sprintf("%d-%d-%d", record.Wins, record.Losses, record.Draws))
+			}
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func shorten(name string, width int) string {
+	if len(name) <= width {
+		return name
+	}
+	return name[:width]
+}
@@ -0,0 +1,423 @@
+// Package tournament plays agents against each other over many games, in a
+// round-robin or a Swiss system, rotating their seats and the deal, and keeps
+// every finished game in a checkpoint file so that an interrupted tournament
+// resumes where it stopped.
+package tournament
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/protocol"
+)
+
+// CheckpointVersion is bumped whenever the checkpoint file changes shape.
+const CheckpointVersion = 1
+
+type Format string
+
+const (
+	// RoundRobin has every table of Players entrants play a game from every
+	// seat rotation, once per round
+	RoundRobin Format = "round-robin"
+	// Swiss pairs the entrants by their standings before every round, and
+	// every table plays a game from every seat rotation
+	Swiss Format = "swiss"
+)
+
+// Entrant is an agent configuration taking part: a difficulty level and a
+// personality, or a bot program playing on the built-in AI of its level
+// once it fails.
+type Entrant struct {
+	Name    string   `json:"name"`
+	Level   ai.Level `json:"level"`
+	Profile string   `json:"profile"`
+	Bot     []string `json:"bot,omitempty"`
+}
+
+// ParseEntrant reads an entrant as "[name=]level[/profile]" or
+// "[name=]bot:command", as in "hard/aggressive" or "echo=bot:./echo -v". The
+// name is the spec itself when not given.
+func ParseEntrant(spec string) (Entrant, error) {
+	spec = strings.TrimSpace(spec)
+	name, config, named := strings.Cut(spec, "=")
+	if !named {
+		name, config = spec, spec
+	}
+	entrant := Entrant{Name: strings.TrimSpace(name), Level: ai.Medium}
+	if command, ok := strings.CutPrefix(strings.TrimSpace(config), "bot:"); ok {
+		entrant.Bot = strings.Fields(command)
+		if len(entrant.Bot) == 0 {
+			return Entrant{}, fmt.Errorf("%q: no bot command", spec)
+		}
+		return entrant, nil
+	}
+
+	levelName, profile, _ := strings.Cut(config, "/")
+	level, err := ai.LevelByName(strings.TrimSpace(levelName))
+	if err != nil {
+		return Entrant{}, fmt.Errorf("%q: %w", spec, err)
+	}
+	personality, err := ai.PersonalityByName(strings.TrimSpace(profile))
+	if err != nil {
+		return Entrant{}, fmt.Errorf("%q: %w", spec, err)
+	}
+	entrant.Level, entrant.Profile = level, personality.Name
+	return entrant, nil
+}
+
+// Config is a tournament.
+type Config struct {
+	Entrants []Entrant
+	Format   Format
+	Players  int // seats at every table
+	// Rounds is how many times the round-robin schedule is played, or the
+	// rounds of a Swiss tournament
+	Rounds int
+	Seed   int64
+	// Workers is the number of games played at once, runtime.NumCPU() if 0
+	Workers int
+	// Checkpoint, when set, is the file every finished game is kept in; a
+	// tournament started again with it plays only the games left
+	Checkpoint string
+	// BotTimeout bounds every decision of a bot, protocol.DefaultTimeout if 0
+	BotTimeout time.Duration
+	// Progress, when set, is told every game played
+	Progress func(Game)
+}
+
+// Game is a game of the tournament and what came of it.
+type Game struct {
+	Number int   `json:"number"`
+	Round  int   `json:"round"`
+	Seats  []int `json:"seats"` // entrant playing every seat
+	Dealer int   `json:"dealer"`
+	Seed   int64 `json:"seed"`
+	Scores []int `json:"scores"`
+	Ranks  []int `json:"ranks"`
+	// the bids every seat made exactly, of the bids of the game
+	Exact []int `json:"exact"`
+	Bids  int   `json:"bids"`
+	// Faults tells, by entrant, why the bots stopped during the game were
+	Faults map[string]string `json:"faults,omitempty"`
+}
+
+// checkpoint is the file a tournament is kept in.
+type checkpoint struct {
+	Version  int       `json:"version"`
+	Entrants []Entrant `json:"entrants"`
+	Format   Format    `json:"format"`
+	Players  int       `json:"players"`
+	Rounds   int       `json:"rounds"`
+	Seed     int64     `json:"seed"`
+	Games    []Game    `json:"games"`
+}
+
+// Run plays the tournament, or the games its checkpoint lacks, and returns
+// every game in the order of the schedule.
+func Run(config Config) ([]Game, error) {
+	if err := config.check(); err != nil {
+		return nil, err
+	}
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+
+	done, err := config.resume()
+	if err != nil {
+		return nil, err
+	}
+	played := make(map[int]Game, len(done))
+	for _, g := range done {
+		played[g.Number] = g
+	}
+
+	var games []Game
+	for round := range config.Rounds {
+		var schedule []Game
+		if config.Format == Swiss {
+			schedule = config.swissRound(round, games)
+		} else {
+			schedule = config.roundRobinRound(round)
+		}
+		results, err := config.play(schedule, played)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, results...)
+	}
+	return games, nil
+}
+
+func (config Config) check() error {
+	switch {
+	case config.Format != RoundRobin && config.Format != Swiss:
+		return fmt.Errorf("unknown format %q: %s or %s", config.Format, RoundRobin, Swiss)
+	case config.Players < 3 || config.Players > 6:
+		return errors.New("a game is played by 3 to 6 players")
+	case len(config.Entrants) < config.Players:
+		return fmt.Errorf("%d entrants can't fill a table of %d", len(config.Entrants), config.Players)
+	case config.Format == Swiss && len(config.Entrants)%config.Players != 0:
+		return fmt.Errorf("a Swiss round seats every entrant: %d entrants don't make tables of %d", len(config.Entrants), config.Players)
+	case config.Rounds < 1:
+		return errors.New("a tournament has at least a round")
+	}
+	names := make(map[string]bool)
+	for _, e := range config.Entrants {
+		if names[e.Name] {
+			return fmt.Errorf("two entrants are called %q", e.Name)
+		}
+		names[e.Name] = true
+	}
+	return nil
+}
+
+// resume reads the games of the checkpoint, none when there is no file yet.
+func (config Config) resume() ([]Game, error) {
+	if config.Checkpoint == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(config.Checkpoint)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.Checkpoint, err)
+	}
+	if c.Version != CheckpointVersion {
+		return nil, fmt.Errorf("%s holds a checkpoint of version %d, this build reads version %d", config.Checkpoint, c.Version, CheckpointVersion)
+	}
+	if !slices.EqualFunc(c.Entrants, config.Entrants, sameEntrant) || c.Format != config.Format || c.Players != config.Players || c.Rounds != config.Rounds || c.Seed != config.Seed {
+		return nil, fmt.Errorf("%s is the checkpoint of another tournament", config.Checkpoint)
+	}
+	return c.Games, nil
+}
+
+func sameEntrant(a, b Entrant) bool {
+	return a.Name == b.Name && a.Level == b.Level && a.Profile == b.Profile && slices.Equal(a.Bot, b.Bot)
+}
+
+// save writes the checkpoint, through a temporary file so that an
+// interruption never leaves it half written.
+func (config Config) save(games []Game) error {
+	if config.Checkpoint == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(checkpoint{
+		Version:  CheckpointVersion,
+		Entrants: config.Entrants,
+		Format:   config.Format,
+		Players:  config.Players,
+		Rounds:   config.Rounds,
+		Seed:     config.Seed,
+		Games:    games,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp := config.Checkpoint + ".tmp"
+	if err := os.WriteFile(temp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temp, config.Checkpoint)
+}
+
+// roundRobinRound schedules every table of Players entrants, each playing a
+// game from every rotation of its seats. The first deal moves on from round
+// to round.
+func (config Config) roundRobinRound(round int) []Game {
+	var schedule []Game
+	for _, table := range combinations(len(config.Entrants), config.Players) {
+		schedule = append(schedule, config.rotations(round, table, round*config.perRound()+len(schedule))...)
+	}
+	return schedule
+}
+
+// perRound is the number of games of a round.
+func (config Config) perRound() int {
+	if config.Format == Swiss {
+		return len(config.Entrants)
+	}
+	return len(combinations(len(config.Entrants), config.Players)) * config.Players
+}
+
+// swissRound seats the entrants by their standings after the games so far,
+// the first round in an order drawn from the seed, and has every table play
+// a game from every rotation of its seats.
+func (config Config) swissRound(round int, games []Game) []Game {
+	order := make([]int, len(config.Entrants))
+	for i := range order {
+		order[i] = i
+	}
+	if round == 0 {
+		rand.New(rand.NewSource(config.Seed)).Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	} else {
+		rows := Standings(config.Entrants, games).Rows
+		for i, row := range rows {
+			order[i] = row.Entrant
+		}
+	}
+
+	var schedule []Game
+	for start := 0; start < len(order); start += config.Players {
+		schedule = append(schedule, config.rotations(round, order[start:start+config.Players], round*config.perRound()+len(schedule))...)
+	}
+	return schedule
+}
+
+// rotations schedules a game of the table from every rotation of its seats,
+// numbered from number.
+func (config Config) rotations(round int, table []int, number int) []Game {
+	games := make([]Game, config.Players)
+	for shift := range games {
+		seats := make([]int, config.Players)
+		for seat := range seats {
+			seats[seat] = table[(seat+shift)%config.Players]
+		}
+		games[shift] = Game{
+			Number: number + shift,
+			Round:  round,
+			Seats:  seats,
+			Dealer: round % config.Players,
+			Seed:   config.Seed + int64(number+shift),
+		}
+	}
+	return games
+}
+
+// combinations lists every set of k of n entrants, in order.
+func combinations(n, k int) [][]int {
+	var sets [][]int
+	var build func(start int, set []int)
+	build = func(start int, set []int) {
+		if len(set) == k {
+			sets = append(sets, slices.Clone(set))
+			return
+		}
+		for i := start; i < n; i++ {
+			build(i+1, append(set, i))
+		}
+	}
+	build(0, nil)
+	return sets
+}
+
+// play plays the scheduled games that aren't played yet in parallel, adding
+// every one to the checkpoint as it ends, and returns the schedule's games.
+func (config Config) play(schedule []Game, played map[int]Game) ([]Game, error) {
+	var pending []Game
+	for _, g := range schedule {
+		if _, ok := played[g.Number]; !ok {
+			pending = append(pending, g)
+		}
+	}
+
+	jobs := make(chan Game)
+	results := make(chan Game)
+	var wg sync.WaitGroup
+	for range config.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range jobs {
+				results <- config.playGame(g)
+			}
+		}()
+	}
+	go func() {
+		for _, g := range pending {
+			jobs <- g
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var err error
+	for g := range results {
+		played[g.Number] = g
+		if err == nil {
+			err = config.save(sortedGames(played))
+		}
+		if config.Progress != nil {
+			config.Progress(g)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("the checkpoint could not be saved: %w", err)
+	}
+
+	games := make([]Game, len(schedule))
+	for i, g := range schedule {
+		games[i] = played[g.Number]
+	}
+	return games, nil
+}
+
+func sortedGames(played map[int]Game) []Game {
+	games := make([]Game, 0, len(played))
+	for _, g := range played {
+		games = append(games, g)
+	}
+	slices.SortFunc(games, func(a, b Game) int { return a.Number - b.Number })
+	return games
+}
+
+// playGame plays a scheduled game, every agent seeded from the game's seed so
+// that the built-in agents play it again the same way.
+func (config Config) playGame(g Game) Game {
+	agents := make([]ai.Agent, len(g.Seats))
+	bots := make(map[string]*protocol.ExternalAgent)
+	g.Faults = make(map[string]string)
+	for seat, entrant := range g.Seats {
+		e := config.Entrants[entrant]
+		agents[seat] = ai.New(e.Level, ai.PersonalityFor(e.Profile), g.Seed+int64(seat))
+		if len(e.Bot) == 0 {
+			continue
+		}
+		bot, err := protocol.Start(e.Bot, nil, config.BotTimeout, agents[seat])
+		if err != nil {
+			g.Faults[e.Name] = err.Error()
+			continue
+		}
+		agents[seat] = bot
+		bots[e.Name] = bot
+	}
+
+	result := arena.Play(agents, g.Dealer, rand.New(rand.NewSource(g.Seed)))
+	for name, bot := range bots {
+		if bot.Fault() != nil {
+			g.Faults[name] = bot.Fault().Error()
+		}
+		bot.Close()
+	}
+
+	if len(g.Faults) == 0 {
+		g.Faults = nil
+	}
+	g.Scores, g.Ranks = result.Scores, result.Ranks
+	g.Bids = len(result.Bids)
+	g.Exact = make([]int, len(g.Seats))
+	for turn := range result.Bids {
+		for seat, bid := range result.Bids[turn] {
+			if bid == result.Won[turn][seat] {
+				g.Exact[seat]++
+			}
+		}
+	}
+	return g
+}
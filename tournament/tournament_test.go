@@ -0,0 +1,120 @@
+package tournament_test
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/tournament"
+)
+
+// TestTournament plays a round-robin, resumes it from a checkpoint cut short
+// and checks the standings add the games up
+func TestTournament(t *testing.T) {
+	var entrants []tournament.Entrant
+	for _, spec := range []string{"easy", "medium", "calm=medium/conservative", "medium/aggressive"} {
+		entrant, err := tournament.ParseEntrant(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entrants = append(entrants, entrant)
+	}
+	if entrants[2].Name != "calm" || entrants[3].Profile != ai.Aggressive.Name {
+		t.Fatalf("parsed the entrants as %+v", entrants)
+	}
+	if entrant, err := tournament.ParseEntrant("tree=ismcts"); err != nil || entrant.Level != ai.Tree {
+		t.Errorf("parsed the ismcts entrant as %+v, %v", entrant, err)
+	}
+
+	config := tournament.Config{Entrants: entrants, Format: tournament.RoundRobin, Players: 3, Rounds: 1, Seed: 3, Workers: 3, Checkpoint: t.TempDir() + "/tournament.json"}
+	games, err := tournament.Run(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 4 tables of 3 entrants, each from 3 rotations of its seats
+	if len(games) != 12 {
+		t.Fatalf("played %d games", len(games))
+	}
+
+	// Half the games are lost, and only they are played again
+	data, err := os.ReadFile(config.Checkpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cut map[string]any
+	if err := json.Unmarshal(data, &cut); err != nil {
+		t.Fatal(err)
+	}
+	cut["games"] = cut["games"].([]any)[:6]
+	if data, err = json.Marshal(cut); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config.Checkpoint, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	replayed := 0
+	config.Progress = func(tournament.Game) { replayed++ }
+	resumed, err := tournament.Run(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed != 6 || !reflect.DeepEqual(resumed, games) {
+		t.Errorf("resuming played %d games again and came to other games", replayed)
+	}
+	config.Seed++
+	if _, err := tournament.Run(config); err == nil {
+		t.Error("resumed the checkpoint of another tournament")
+	}
+
+	table := tournament.Standings(entrants, games)
+	wins, rotations := 0, 0
+	for _, row := range table.Rows {
+		wins += row.Wins
+		if row.Games != 9 || row.Bids != 9*arena.Turns(3) || row.BidAccuracy() <= 0 {
+			t.Errorf("%s played %d games and %d bids", entrants[row.Entrant].Name, row.Games, row.Bids)
+		}
+		for other, record := range table.HeadToHead[row.Entrant] {
+			back := table.HeadToHead[other][row.Entrant]
+			if record.Wins != back.Losses || record.Draws != back.Draws {
+				t.Errorf("head to head %v against %v", record, back)
+			}
+			if other != row.Entrant && record.Wins+record.Losses+record.Draws != 6 {
+				t.Errorf("%s met %s %d times", entrants[row.Entrant].Name, entrants[other].Name, record.Wins+record.Losses+record.Draws)
+			}
+		}
+	}
+	for _, g := range games {
+		if g.Seats[0] == games[g.Number-g.Number%3].Seats[g.Number%3] {
+			rotations++
+		}
+	}
+	if wins < len(games) || rotations != len(games) {
+		t.Errorf("%d wins in %d games, %d rotated seatings", wins, len(games), rotations)
+	}
+	if err := table.Write(io.Discard); err != nil {
+		t.Error(err)
+	}
+
+	// A Swiss round seats every entrant once at every table rotation
+	swiss, err := tournament.Run(tournament.Config{Entrants: append(entrants[:3:3], entrants[:3]...), Format: tournament.Swiss, Players: 3, Rounds: 2, Seed: 1})
+	if err == nil {
+		t.Errorf("two entrants of the same name played %d games", len(swiss))
+	}
+	six := slices.Clone(entrants)
+	for _, spec := range []string{"easy/aggressive", "easy/conservative"} {
+		entrant, _ := tournament.ParseEntrant(spec)
+		six = append(six, entrant)
+	}
+	if swiss, err = tournament.Run(tournament.Config{Entrants: six, Format: tournament.Swiss, Players: 3, Rounds: 2, Seed: 1}); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range tournament.Standings(six, swiss).Rows {
+		if row.Games != 6 {
+			t.Errorf("%s played %d of the Swiss games", six[row.Entrant].Name, row.Games)
+		}
+	}
+}
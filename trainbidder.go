@@ -0,0 +1,149 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"wizard/ai"
+	"wizard/arena"
+	"wizard/engine"
+	"wizard/gamelog"
+)
+
+// runTrainBidder fits the bid model on the game log, saves it and reports how
+// it compares with the heuristic bidder.
+func runTrainBidder(args []string) {
+	flags := flag.NewFlagSet("train-bidder", flag.ExitOnError)
+	log := flags.String("log", "games.jsonl", "game log to learn from")
+	selfPlay := flags.Int("self-play", 0, "games of medium players added to the log's, for a start without many logged games")
+	players := flags.Int("players", 4, "seats at the table of the self-play and comparison games")
+	holdout := flags.Float64("holdout", 0.2, "share of the games whose turns are kept out of the fit to compare the bidders on")
+	ridge := flags.Float64("ridge", 1, "penalty on the size of the coefficients")
+	games := flags.Int("games", 100, "games played between the learned and the heuristic bidders for the report")
+	seed := flags.Int64("seed", 1, "seed of the split and of the games")
+	out := flags.String("out", "bidder.json", "file the model is saved to")
+	flags.Parse(args)
+
+	if *players < 3 || *players > 6 {
+		fmt.Fprintln(os.Stderr, "a game is played by 3 to 6 players")
+		os.Exit(2)
+	}
+	logged, err := gamelog.Read(*log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// The turns of a game are split together, so that the held-out turns
+	// come from games the fit has seen nothing of
+	var byGame [][]ai.BidSample
+	for _, game := range logged {
+		byGame = append(byGame, ai.BidSamples(game.Events))
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	for range *selfPlay {
+		agents := make([]ai.Agent, *players)
+		for seat := range agents {
+			agents[seat] = ai.New(ai.Medium, ai.Balanced, rng.Int63())
+		}
+		byGame = append(byGame, ai.BidSamples(arena.Play(agents, 0, rng).Events))
+	}
+
+	rng.Shuffle(len(byGame), func(i, j int) { byGame[i], byGame[j] = byGame[j], byGame[i] })
+	split := len(byGame) - int(float64(len(byGame))*math.Max(0, math.Min(1, *holdout)))
+	train, test := slices.Concat(byGame[:split]...), slices.Concat(byGame[split:]...)
+
+	model, err := ai.FitBidModel(train, *ridge)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v: play more games or add -self-play games\n", err)
+		os.Exit(1)
+	}
+	if err := model.Save(*out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Fitted on %d turns (%d games logged, %d self-played), saved to %s\n", len(train), len(logged), *selfPlay, *out)
+	fmt.Printf("Tricks spread around the prediction: %.2f\n\n", model.Spread)
+
+	if len(test) > 0 {
+		heuristic := ai.New(ai.Medium, ai.Balanced, *seed)
+		fmt.Printf("Held-out turns (%d, of %d games), bidding on the logged hands:\n", len(test), len(byGame)-split)
+		fmt.Printf("%-10s %8s %10s %10s\n", "bidder", "exact", "off by", "points")
+		reportBids("learned", test, model.Bid)
+		reportBids("heuristic", test, heuristic.Bid)
+		reportBids("logged", test, nil)
+		fmt.Println()
+	}
+
+	if *games > 0 {
+		compareAtTable(model, *players, *games, rng.Int63())
+	}
+}
+
+// reportBids scores the bids of a bidder against the tricks the seats took
+// in the log; a nil bidder is scored on the logged bids.
+func reportBids(name string, samples []ai.BidSample, bid func(*engine.PlayerView) int) {
+	exact, off, points := 0, 0, 0
+	for _, s := range samples {
+		b := s.Bid
+		if bid != nil {
+			b = bid(&s.View)
+		}
+		if b == s.Tricks {
+			exact++
+		}
+		off += max(b-s.Tricks, s.Tricks-b)
+		points += engine.Score(b, s.Tricks)
+	}
+	n := float64(len(samples))
+	fmt.Printf("%-10s %7.1f%% %10.2f %10.2f\n", name, 100*float64(exact)/n, float64(off)/n, float64(points)/n)
+}
+
+// compareAtTable plays games with the learned bidder in every other seat and
+// the medium level in the rest, swapping the seats from one game to the next.
+func compareAtTable(model *ai.BidModel, players, games int, seed int64) {
+	type tally struct{ seats, points, exact, turns int }
+	var learned, heuristic tally
+
+	rng := rand.New(rand.NewSource(seed))
+	for game := range games {
+		agents := make([]ai.Agent, players)
+		isLearned := make([]bool, players)
+		for seat := range agents {
+			isLearned[seat] = (seat+game)%2 == 0
+			if isLearned[seat] {
+				agents[seat] = ai.NewLearnedBidder(model, ai.Balanced)
+			} else {
+				agents[seat] = ai.New(ai.Medium, ai.Balanced, rng.Int63())
+			}
+		}
+
+		result := arena.Play(agents, game%players, rng)
+		for seat, score := range result.Scores {
+			t := &heuristic
+			if isLearned[seat] {
+				t = &learned
+			}
+			t.seats++
+			t.points += score
+			for turn := range result.Bids {
+				t.turns++
+				if result.Bids[turn][seat] == result.Won[turn][seat] {
+					t.exact++
+				}
+			}
+		}
+	}
+
+	fmt.Printf("At the table, %d games of %d players:\n", games, players)
+	fmt.Printf("%-10s %8s %12s\n", "bidder", "exact", "avg score")
+	for _, row := range []struct {
+		name string
+		t    tally
+	}{{"learned", learned}, {"heuristic", heuristic}} {
+		fmt.Printf("%-10s %7.1f%% %12.1f\n", row.name, 100*float64(row.t.exact)/float64(row.t.turns), float64(row.t.points)/float64(row.t.seats))
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"wizard/ai"
+	"wizard/tune"
+)
+
+// runTune searches the AI weights by self-play and saves the best as a profile.
+func runTune(args []string) {
+	flags := flag.NewFlagSet("tune", flag.ExitOnError)
+	generations := flags.Int("generations", 20, "generations of the search")
+	population := flags.Int("population", 12, "candidate weights tried every generation")
+	games := flags.Int("games", 24, "games every candidate plays in a generation")
+	players := flags.Int("players", 4, "seats at the table")
+	levelName := flags.String("level", "medium", "difficulty of the computer players: easy, medium, hard or expert")
+	seed := flags.Int64("seed", 1, "seed of the search and the deals")
+	workers := flags.Int("workers", 0, "games played at once, 0 for one per CPU")
+	start := flags.String("start", "", "weights profile to start from instead of the default weights")
+	out := flags.String("out", "weights.json", "file the tuned weights are saved to")
+	name := flags.String("name", "tuned", "name of the saved profile")
+	flags.Parse(args)
+
+	level, err := ai.LevelByName(*levelName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *players < 3 || *players > 6 {
+		fmt.Fprintln(os.Stderr, "a game is played by 3 to 6 players")
+		os.Exit(2)
+	}
+	weights := ai.DefaultWeights
+	if *start != "" {
+		if weights, err = ai.LoadWeights(*start); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	best, margin := tune.Run(tune.Config{
+		Generations: *generations,
+		Population:  *population,
+		Games:       *games,
+		Players:     *players,
+		Level:       level,
+		Seed:        *seed,
+		Workers:     *workers,
+		Start:       weights,
+		Progress: func(g tune.Generation) {
+			fmt.Printf("generation %d: mean %+.1f, best %+.1f points per game\n", g.Number, g.Fitness, g.Best)
+		},
+	})
+
+	best.Version = ai.WeightsVersion
+	best.Name = *name
+	if err := best.Save(*out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Saved the weights to %s (%+.1f points per game over the starting weights, measured again on %d games)\n", *out, margin, *games)
+}
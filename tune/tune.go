@@ -0,0 +1,226 @@
+// Package tune optimises the weights of the heuristic AI by self-play: an
+// evolution strategy in the manner of CMA-ES, with a diagonal covariance,
+// searches the weights that score best against the ones it started from.
+package tune
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"slices"
+	"sync"
+	"wizard/ai"
+	"wizard/arena"
+)
+
+// Config is a tuning run.
+type Config struct {
+	Generations int
+	Population  int // candidates tried every generation
+	Games       int // games every candidate plays in a generation
+	Players     int
+	Level       ai.Level
+	Seed        int64
+	Workers     int        // games played at once, runtime.NumCPU() if 0
+	Start       ai.Weights // the weights the search starts from and plays against
+	// Progress, when set, is told the outcome of every generation
+	Progress func(Generation)
+}
+
+// Generation is the outcome of one generation of the search.
+type Generation struct {
+	Number  int
+	Mean    ai.Weights // where the search stands
+	Fitness float64    // the mean's points per game over the opponents' average
+	Best    float64    // the best candidate's
+}
+
+// param is one tuned weight and the range it is searched in.
+type param struct {
+	field    func(w *ai.Weights) *float64
+	min, max float64
+}
+
+var params = []param{
+	{func(w *ai.Weights) *float64 { return &w.SpendRate }, 0.5, 5},
+	{func(w *ai.Weights) *float64 { return &w.OffSuitFactor }, 0.2, 1},
+	{func(w *ai.Weights) *float64 { return &w.BidScale }, 0.6, 1.4},
+	{func(w *ai.Weights) *float64 { return &w.TableShading }, 0, 1.5},
+	{func(w *ai.Weights) *float64 { return &w.DuckingThreat }, 0, 1},
+	{func(w *ai.Weights) *float64 { return &w.WizardReserve }, 0.5, 4},
+}
+
+// The search runs on the weights scaled to [0, 1] over their ranges.
+func encode(w ai.Weights) []float64 {
+	x := make([]float64, len(params))
+	for i, p := range params {
+		x[i] = (*p.field(&w) - p.min) / (p.max - p.min)
+	}
+	return x
+}
+
+func decode(x []float64, base ai.Weights) ai.Weights {
+	w := base
+	for i, p := range params {
+		*p.field(&w) = p.min + math.Max(0, math.Min(1, x[i]))*(p.max-p.min)
+	}
+	return w
+}
+
+const (
+	startStep = 0.15 // the initial step size on the scaled weights
+	minStep   = 0.01
+	stepRate  = 0.3 // how fast the step sizes follow the selected steps
+)
+
+// Run searches the weights and returns the best candidate evaluated, with its
+// points per game over the starting weights measured again on games of its
+// own: the score it was picked by is the luckiest of many. Every candidate of
+// a generation plays the same deals from the same seats, so that they are
+// told apart by their play rather than by their cards.
+func Run(config Config) (ai.Weights, float64) {
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+	population := max(config.Population, 2)
+	selected := population / 2
+
+	// Log-linear recombination weights of the selected candidates
+	recombination := make([]float64, selected)
+	total := 0.0
+	for i := range recombination {
+		recombination[i] = math.Log(float64(selected)+0.5) - math.Log(float64(i+1))
+		total += recombination[i]
+	}
+	for i := range recombination {
+		recombination[i] /= total
+	}
+
+	rng := rand.New(rand.NewSource(config.Seed))
+	mean := encode(config.Start)
+	steps := make([]float64, len(params))
+	for i := range steps {
+		steps[i] = startStep
+	}
+
+	best, bestScore := mean, math.Inf(-1)
+	for number := 1; number <= config.Generations; number++ {
+		// The mean goes first, so that its fitness is measured on the same games
+		candidates := [][]float64{mean}
+		for range population {
+			x := make([]float64, len(mean))
+			for i := range x {
+				x[i] = math.Max(0, math.Min(1, mean[i]+steps[i]*rng.NormFloat64()))
+			}
+			candidates = append(candidates, x)
+		}
+
+		scores := evaluate(config, candidates, rng.Int63())
+		for i, score := range scores {
+			if score > bestScore {
+				best, bestScore = candidates[i], score
+			}
+		}
+
+		order := make([]int, population)
+		for i := range order {
+			order[i] = i + 1
+		}
+		slices.SortStableFunc(order, func(a, b int) int {
+			switch {
+			case scores[a] > scores[b]:
+				return -1
+			case scores[a] < scores[b]:
+				return 1
+			}
+			return 0
+		})
+
+		next := make([]float64, len(mean))
+		for rank, i := range order[:selected] {
+			for d := range next {
+				next[d] += recombination[rank] * candidates[i][d]
+			}
+		}
+		for d := range steps {
+			variance := 0.0
+			for rank, i := range order[:selected] {
+				step := candidates[i][d] - mean[d]
+				variance += recombination[rank] * step * step
+			}
+			steps[d] = math.Max(minStep, math.Sqrt((1-stepRate)*steps[d]*steps[d]+stepRate*variance))
+		}
+		mean = next
+
+		if config.Progress != nil {
+			config.Progress(Generation{
+				Number:  number,
+				Mean:    decode(mean, config.Start),
+				Fitness: scores[0],
+				Best:    scores[order[0]],
+			})
+		}
+	}
+	return decode(best, config.Start), evaluate(config, [][]float64{best}, rng.Int63())[0]
+}
+
+// evaluate plays every candidate's games in parallel and returns the average
+// margin of every candidate over the seats playing the starting weights.
+func evaluate(config Config, candidates [][]float64, seed int64) []float64 {
+	type game struct{ candidate, number int }
+	games := make(chan game)
+	margins := make([][]float64, len(candidates))
+	for i := range margins {
+		margins[i] = make([]float64, config.Games)
+	}
+
+	var wg sync.WaitGroup
+	for range config.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range games {
+				margins[g.candidate][g.number] = play(config, decode(candidates[g.candidate], config.Start), seed+int64(g.number), g.number)
+			}
+		}()
+	}
+	for candidate := range candidates {
+		for number := range config.Games {
+			games <- game{candidate, number}
+		}
+	}
+	close(games)
+	wg.Wait()
+
+	scores := make([]float64, len(candidates))
+	for i, m := range margins {
+		for _, margin := range m {
+			scores[i] += margin
+		}
+		scores[i] /= float64(config.Games)
+	}
+	return scores
+}
+
+// play has the weights play one game from the seat number picks, against
+// seats on the starting weights, and returns its points over their average.
+func play(config Config, weights ai.Weights, seed int64, number int) float64 {
+	seat := number % config.Players
+	agents := make([]ai.Agent, config.Players)
+	for i := range agents {
+		if i == seat {
+			agents[i] = ai.NewTuned(config.Level, ai.Balanced, weights, seed+int64(i))
+		} else {
+			agents[i] = ai.NewTuned(config.Level, ai.Balanced, config.Start, seed+int64(i))
+		}
+	}
+
+	result := arena.Play(agents, 0, rand.New(rand.NewSource(seed)))
+	others := 0
+	for i, score := range result.Scores {
+		if i != seat {
+			others += score
+		}
+	}
+	return float64(result.Scores[seat]) - float64(others)/float64(config.Players-1)
+}
@@ -0,0 +1,37 @@
+package tune_test
+
+import (
+	"testing"
+	"wizard/ai"
+	"wizard/tune"
+)
+
+// TestTuneWeights checks that the tuning is reproducible from its seed and
+// that the tuned weights survive the trip through their profile
+func TestTuneWeights(t *testing.T) {
+	config := tune.Config{Generations: 2, Population: 4, Games: 3, Players: 5, Level: ai.Medium, Seed: 7, Start: ai.DefaultWeights}
+	first, fitness := tune.Run(config)
+	config.Workers = 1
+	second, again := tune.Run(config)
+	if first != second || fitness != again {
+		t.Errorf("two runs from the same seed gave %+v (%.1f) and %+v (%.1f)", first, fitness, second, again)
+	}
+
+	path := t.TempDir() + "/weights.json"
+	if err := first.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ai.LoadWeights(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded != first {
+		t.Errorf("loaded %+v, saved %+v", loaded, first)
+	}
+
+	first.Version++
+	first.Save(path)
+	if _, err := ai.LoadWeights(path); err == nil {
+		t.Error("weights of another version were loaded")
+	}
+}